@@ -0,0 +1,65 @@
+// Package example has types with methods generated by jessygen
+package example
+
+import "time"
+
+//go:generate go run .. -type=User,Address,Item,Account
+
+type Status string
+
+type Base struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created,omitempty"`
+}
+
+type Meta struct {
+	Source string `json:"source,omitempty"`
+}
+
+type User struct {
+	Base
+	*Meta
+
+	Name     string            `json:"name"`
+	Email    string            `json:"email,omitempty"`
+	Age      int               `json:"age,string"`
+	Score    float64           `json:"score,omitempty"`
+	Ratio    float32           `json:"ratio"`
+	Active   bool              `json:"active"`
+	Status   Status            `json:"status"`
+	Level    uint8             `json:"level"`
+	Tags     []string          `json:"tags"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Key      []byte            `json:"key,bytes=hex"`
+	Home     *Address          `json:"home,omitempty"`
+	Work     Address           `json:"work"`
+	Items    []Item            `json:"items"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Counters map[Status]int    `json:"counters"`
+	Friends  map[string]*User  `json:"friends,omitempty"`
+	Extra    any               `json:"extra,omitempty"`
+	Matrix   [2][2]int         `json:"matrix"`
+	Note     *string           `json:"note"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+type Address struct {
+	City   string     `json:"city"`
+	Zip    *int       `json:"zip,omitempty"`
+	Coords [2]float64 `json:"coords"`
+}
+
+type Item struct {
+	SKU   string  `json:"sku"`
+	Qty   int32   `json:"qty,omitempty"`
+	Price float64 `json:"price,precision=2"`
+}
+
+// Account has id hiding id of embedded Base
+type Account struct {
+	Base
+	ID       int      `json:"id"`
+	Verified *bool    `json:"verified,string"`
+	Limit    *float64 `json:"limit,omitempty,string"`
+}
@@ -0,0 +1,104 @@
+package example
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avpetkun/jessy-go"
+	"github.com/avpetkun/jessy-go/require"
+)
+
+// plainUser has fields of User without generated methods
+type plainUser User
+
+func TestGenerated(t *testing.T) {
+	zip, note := 1234, "note <b>"
+	users := []User{
+		{},
+		{
+			Base: Base{ID: 7, Created: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)},
+			Meta: &Meta{Source: "api"},
+			Name: "Jo \"Doe\"", Email: "jo@example.com", Age: 42, Score: 0.5, Ratio: 1.25,
+			Active: true, Status: "on", Level: 3,
+			Tags: []string{"a", "b"}, Avatar: []byte{1, 2, 3}, Key: []byte{0xab},
+			Home:     &Address{City: "Oslo", Zip: &zip, Coords: [2]float64{59.9, 10.7}},
+			Work:     Address{City: "Bergen"},
+			Items:    []Item{{SKU: "x", Qty: 2, Price: 9.999}, {SKU: "y"}},
+			Labels:   map[string]string{"b": "2", "a": "1"},
+			Counters: map[Status]int{"z": 1, "y": 2},
+			Friends:  map[string]*User{"nil": nil, "ann": {Name: "Ann"}},
+			Extra:    []any{1.5, "s"},
+			Matrix:   [2][2]int{{1, 2}, {3, 4}},
+			Note:     &note,
+			Ignored:  "ignored", internal: "internal",
+		},
+	}
+	for _, u := range users {
+		expected, err := jessy.Marshal((*plainUser)(&u))
+		require.NoError(t, err)
+		data, err := jessy.Marshal(u)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(data))
+
+		var decoded User
+		require.NoError(t, jessy.Unmarshal(data, &decoded))
+		var plain User
+		require.NoError(t, jessy.Unmarshal(data, (*plainUser)(&plain)))
+		require.Equal(t, plain, decoded)
+	}
+
+	inputs := []string{
+		`null`,
+		`{}`,
+		`{"NAME":"x","Id":5,"age":"12","source":"s","home":null,"tags":null,"matrix":[[1],[2,3,4]],"unknown":[1,{}]}`,
+		`{"avatar":[1,2],"note":null,"counters":{"a":1},"friends":{"b":{"work":{"city":"c"}}},"items":[{"price":1}]}`,
+	}
+	for _, data := range inputs {
+		var decoded User
+		require.NoError(t, jessy.Unmarshal([]byte(data), &decoded))
+		var plain User
+		require.NoError(t, jessy.Unmarshal([]byte(data), (*plainUser)(&plain)))
+		require.Equal(t, plain, decoded)
+	}
+
+	invalid := []string{`[]`, `{"age":12}`, `{"level":300}`, `{"name":1}`, `{"tags":{}}`, `{"key":"xyz"}`, `{} 1`}
+	for _, data := range invalid {
+		var decoded User
+		require.NotEqual(t, nil, decoded.UnmarshalJSON([]byte(data)))
+	}
+}
+
+// plainAccount has fields of Account without generated methods
+type plainAccount Account
+
+func TestGeneratedDominantFields(t *testing.T) {
+	verified, limit := true, 2.5
+	accounts := []Account{
+		{Verified: &verified},
+		{Base: Base{ID: 1}, ID: 2, Verified: &verified, Limit: &limit},
+	}
+	for _, a := range accounts {
+		expected, err := jessy.Marshal((*plainAccount)(&a))
+		require.NoError(t, err)
+		data, err := jessy.Marshal(a)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(data))
+	}
+
+	inputs := []string{
+		`{"id":5}`,
+		`{"ID":6,"verified":"false","limit":"1.5"}`,
+		`{"id":7,"verified":null,"limit":null}`,
+	}
+	for _, data := range inputs {
+		var decoded Account
+		require.NoError(t, jessy.Unmarshal([]byte(data), &decoded))
+		var plain Account
+		require.NoError(t, jessy.Unmarshal([]byte(data), (*plainAccount)(&plain)))
+		require.Equal(t, plain, decoded)
+	}
+
+	var decoded Account
+	require.NoError(t, jessy.Unmarshal([]byte(`{"id":5}`), &decoded))
+	require.Equal(t, Account{ID: 5}, decoded)
+}
@@ -0,0 +1,608 @@
+// Code generated by jessygen; DO NOT EDIT.
+
+package example
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/avpetkun/jessy-go"
+	"github.com/avpetkun/jessy-go/zgo"
+)
+
+// AppendJSON appends JSON encoding of v, it implements jessy.AppendMarshaler
+func (v User) AppendJSON(dst []byte) ([]byte, error) {
+	w := jessy.NewWriter(dst)
+	v.WriteJSON(&w)
+	return w.Result()
+}
+
+// WriteJSON writes v as JSON object
+func (v *User) WriteJSON(w *jessy.Writer) {
+	w.ObjectStart()
+	w.Field("created", &v.Base.Created, "omitempty")
+	w.Key("id")
+	w.Int64(v.Base.ID)
+	if v.Meta != nil && v.Meta.Source != "" {
+		w.Key("source")
+		w.String(v.Meta.Source)
+	}
+	w.Key("active")
+	w.Bool(v.Active)
+	w.Key("age")
+	w.Quoted(func(q *jessy.Writer) { q.Int64(int64(v.Age)) })
+	if len(v.Avatar) != 0 {
+		w.Key("avatar")
+		w.Bytes(v.Avatar)
+	}
+	w.Key("counters")
+	if v.Counters == nil {
+		w.Null()
+	} else {
+		keys0 := make([]Status, 0, len(v.Counters))
+		for k0 := range v.Counters {
+			keys0 = append(keys0, k0)
+		}
+		slices.Sort(keys0)
+		w.ObjectStart()
+		for _, k0 := range keys0 {
+			w.Key(string(k0))
+			w.Int64(int64(v.Counters[k0]))
+		}
+		w.ObjectEnd()
+	}
+	if v.Email != "" {
+		w.Key("email")
+		w.String(v.Email)
+	}
+	w.Field("extra", &v.Extra, "omitempty")
+	if v.Friends != nil {
+		w.Key("friends")
+		keys0 := make([]string, 0, len(v.Friends))
+		for k0 := range v.Friends {
+			keys0 = append(keys0, k0)
+		}
+		slices.Sort(keys0)
+		w.ObjectStart()
+		for _, k0 := range keys0 {
+			w.Key(k0)
+			if v.Friends[k0] == nil {
+				w.Null()
+			} else {
+				v.Friends[k0].WriteJSON(w)
+			}
+		}
+		w.ObjectEnd()
+	}
+	if v.Home != nil {
+		w.Key("home")
+		v.Home.WriteJSON(w)
+	}
+	w.Key("items")
+	w.ArrayStart()
+	for i0 := range v.Items {
+		v.Items[i0].WriteJSON(w)
+	}
+	w.ArrayEnd()
+	w.Field("key", &v.Key, "bytes=hex")
+	if v.Labels != nil {
+		w.Key("labels")
+		keys0 := make([]string, 0, len(v.Labels))
+		for k0 := range v.Labels {
+			keys0 = append(keys0, k0)
+		}
+		slices.Sort(keys0)
+		w.ObjectStart()
+		for _, k0 := range keys0 {
+			w.Key(k0)
+			w.String(v.Labels[k0])
+		}
+		w.ObjectEnd()
+	}
+	w.Key("level")
+	w.Uint64(uint64(v.Level))
+	w.Key("matrix")
+	w.ArrayStart()
+	for i0 := range v.Matrix {
+		w.ArrayStart()
+		for i1 := range v.Matrix[i0] {
+			w.Int64(int64(v.Matrix[i0][i1]))
+		}
+		w.ArrayEnd()
+	}
+	w.ArrayEnd()
+	w.Key("name")
+	w.String(v.Name)
+	w.Key("note")
+	if v.Note == nil {
+		w.Null()
+	} else {
+		w.String(*v.Note)
+	}
+	w.Key("ratio")
+	w.Float32(v.Ratio)
+	if v.Score != 0 {
+		w.Key("score")
+		w.Float64(v.Score)
+	}
+	w.Key("status")
+	w.String(string(v.Status))
+	w.Key("tags")
+	w.ArrayStart()
+	for i0 := range v.Tags {
+		w.String(v.Tags[i0])
+	}
+	w.ArrayEnd()
+	w.Key("work")
+	v.Work.WriteJSON(w)
+	w.ObjectEnd()
+}
+
+// UnmarshalJSON decodes JSON object into v, it implements json.Unmarshaler
+func (v *User) UnmarshalJSON(data []byte) error {
+	it := jessy.NewIterator(data)
+	v.ReadJSON(it)
+	return it.End()
+}
+
+// ReadJSON reads JSON object into v matching fields by exact and then case-insensitive keys
+func (v *User) ReadJSON(it *jessy.Iterator) {
+	it.ReadObject(func(key []byte) bool {
+		if name := zgo.B2S(key); !v.readJSONField(it, name) {
+			for _, k := range [...]string{"created", "id", "source", "active", "age", "avatar", "counters", "email", "extra", "friends", "home", "items", "key", "labels", "level", "matrix", "name", "note", "ratio", "score", "status", "tags", "work"} {
+				if strings.EqualFold(k, name) {
+					v.readJSONField(it, k)
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+func (v *User) readJSONField(it *jessy.Iterator, key string) bool {
+	switch key {
+	case "created":
+		it.ReadField(&v.Base.Created, "omitempty")
+	case "id":
+		if !it.ReadNull() {
+			v.Base.ID = it.ReadInt64()
+		}
+	case "source":
+		if v.Meta == nil {
+			v.Meta = new(Meta)
+		}
+		if !it.ReadNull() {
+			v.Meta.Source = it.ReadString()
+		}
+	case "active":
+		if !it.ReadNull() {
+			v.Active = it.ReadBool()
+		}
+	case "age":
+		if !it.ReadNull() {
+			it.ReadQuoted(func(q *jessy.Iterator) {
+				if !q.ReadNull() {
+					v.Age = q.ReadInt()
+				}
+			})
+		}
+	case "avatar":
+		if it.ReadNull() {
+			v.Avatar = nil
+		} else {
+			v.Avatar = it.ReadBytes()
+		}
+	case "counters":
+		if it.ReadNull() {
+			v.Counters = nil
+		} else {
+			if v.Counters == nil {
+				v.Counters = make(map[Status]int)
+			}
+			it.ReadObject(func(key []byte) bool {
+				k0 := Status(key)
+				var e0 int
+				if !it.ReadNull() {
+					e0 = it.ReadInt()
+				}
+				v.Counters[k0] = e0
+				return true
+			})
+		}
+	case "email":
+		if !it.ReadNull() {
+			v.Email = it.ReadString()
+		}
+	case "extra":
+		it.ReadField(&v.Extra, "omitempty")
+	case "friends":
+		if it.ReadNull() {
+			v.Friends = nil
+		} else {
+			if v.Friends == nil {
+				v.Friends = make(map[string]*User)
+			}
+			it.ReadObject(func(key []byte) bool {
+				k0 := string(key)
+				var e0 *User
+				if it.ReadNull() {
+					e0 = nil
+				} else {
+					if e0 == nil {
+						e0 = new(User)
+					}
+					e0.ReadJSON(it)
+				}
+				v.Friends[k0] = e0
+				return true
+			})
+		}
+	case "home":
+		if it.ReadNull() {
+			v.Home = nil
+		} else {
+			if v.Home == nil {
+				v.Home = new(Address)
+			}
+			v.Home.ReadJSON(it)
+		}
+	case "items":
+		if it.ReadNull() {
+			v.Items = nil
+		} else {
+			v.Items = v.Items[:0]
+			if v.Items == nil {
+				v.Items = []Item{}
+			}
+			it.ReadArray(func() bool {
+				var e0 Item
+				e0.ReadJSON(it)
+				v.Items = append(v.Items, e0)
+				return true
+			})
+		}
+	case "key":
+		it.ReadField(&v.Key, "bytes=hex")
+	case "labels":
+		if it.ReadNull() {
+			v.Labels = nil
+		} else {
+			if v.Labels == nil {
+				v.Labels = make(map[string]string)
+			}
+			it.ReadObject(func(key []byte) bool {
+				k0 := string(key)
+				var e0 string
+				if !it.ReadNull() {
+					e0 = it.ReadString()
+				}
+				v.Labels[k0] = e0
+				return true
+			})
+		}
+	case "level":
+		if !it.ReadNull() {
+			v.Level = it.ReadUint8()
+		}
+	case "matrix":
+		if !it.ReadNull() {
+			i0 := 0
+			it.ReadArray(func() bool {
+				if i0 < len(v.Matrix) {
+					if !it.ReadNull() {
+						i1 := 0
+						it.ReadArray(func() bool {
+							if i1 < len(v.Matrix[i0]) {
+								if !it.ReadNull() {
+									v.Matrix[i0][i1] = it.ReadInt()
+								}
+							}
+							i1++
+							return true
+						})
+						if i1 < len(v.Matrix[i0]) {
+							clear(v.Matrix[i0][i1:])
+						}
+					}
+				}
+				i0++
+				return true
+			})
+			if i0 < len(v.Matrix) {
+				clear(v.Matrix[i0:])
+			}
+		}
+	case "name":
+		if !it.ReadNull() {
+			v.Name = it.ReadString()
+		}
+	case "note":
+		if it.ReadNull() {
+			v.Note = nil
+		} else {
+			if v.Note == nil {
+				v.Note = new(string)
+			}
+			if !it.ReadNull() {
+				*v.Note = it.ReadString()
+			}
+		}
+	case "ratio":
+		if !it.ReadNull() {
+			v.Ratio = it.ReadFloat32()
+		}
+	case "score":
+		if !it.ReadNull() {
+			v.Score = it.ReadFloat64()
+		}
+	case "status":
+		if !it.ReadNull() {
+			v.Status = Status(it.ReadString())
+		}
+	case "tags":
+		if it.ReadNull() {
+			v.Tags = nil
+		} else {
+			v.Tags = v.Tags[:0]
+			if v.Tags == nil {
+				v.Tags = []string{}
+			}
+			it.ReadArray(func() bool {
+				var e0 string
+				if !it.ReadNull() {
+					e0 = it.ReadString()
+				}
+				v.Tags = append(v.Tags, e0)
+				return true
+			})
+		}
+	case "work":
+		v.Work.ReadJSON(it)
+	default:
+		return false
+	}
+	return true
+}
+
+// AppendJSON appends JSON encoding of v, it implements jessy.AppendMarshaler
+func (v Address) AppendJSON(dst []byte) ([]byte, error) {
+	w := jessy.NewWriter(dst)
+	v.WriteJSON(&w)
+	return w.Result()
+}
+
+// WriteJSON writes v as JSON object
+func (v *Address) WriteJSON(w *jessy.Writer) {
+	w.ObjectStart()
+	w.Key("city")
+	w.String(v.City)
+	w.Key("coords")
+	w.ArrayStart()
+	for i0 := range v.Coords {
+		w.Float64(v.Coords[i0])
+	}
+	w.ArrayEnd()
+	if v.Zip != nil {
+		w.Key("zip")
+		w.Int64(int64(*v.Zip))
+	}
+	w.ObjectEnd()
+}
+
+// UnmarshalJSON decodes JSON object into v, it implements json.Unmarshaler
+func (v *Address) UnmarshalJSON(data []byte) error {
+	it := jessy.NewIterator(data)
+	v.ReadJSON(it)
+	return it.End()
+}
+
+// ReadJSON reads JSON object into v matching fields by exact and then case-insensitive keys
+func (v *Address) ReadJSON(it *jessy.Iterator) {
+	it.ReadObject(func(key []byte) bool {
+		if name := zgo.B2S(key); !v.readJSONField(it, name) {
+			for _, k := range [...]string{"city", "coords", "zip"} {
+				if strings.EqualFold(k, name) {
+					v.readJSONField(it, k)
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+func (v *Address) readJSONField(it *jessy.Iterator, key string) bool {
+	switch key {
+	case "city":
+		if !it.ReadNull() {
+			v.City = it.ReadString()
+		}
+	case "coords":
+		if !it.ReadNull() {
+			i0 := 0
+			it.ReadArray(func() bool {
+				if i0 < len(v.Coords) {
+					if !it.ReadNull() {
+						v.Coords[i0] = it.ReadFloat64()
+					}
+				}
+				i0++
+				return true
+			})
+			if i0 < len(v.Coords) {
+				clear(v.Coords[i0:])
+			}
+		}
+	case "zip":
+		if it.ReadNull() {
+			v.Zip = nil
+		} else {
+			if v.Zip == nil {
+				v.Zip = new(int)
+			}
+			if !it.ReadNull() {
+				*v.Zip = it.ReadInt()
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// AppendJSON appends JSON encoding of v, it implements jessy.AppendMarshaler
+func (v Item) AppendJSON(dst []byte) ([]byte, error) {
+	w := jessy.NewWriter(dst)
+	v.WriteJSON(&w)
+	return w.Result()
+}
+
+// WriteJSON writes v as JSON object
+func (v *Item) WriteJSON(w *jessy.Writer) {
+	w.ObjectStart()
+	w.Field("price", &v.Price, "precision=2")
+	if v.Qty != 0 {
+		w.Key("qty")
+		w.Int64(int64(v.Qty))
+	}
+	w.Key("sku")
+	w.String(v.SKU)
+	w.ObjectEnd()
+}
+
+// UnmarshalJSON decodes JSON object into v, it implements json.Unmarshaler
+func (v *Item) UnmarshalJSON(data []byte) error {
+	it := jessy.NewIterator(data)
+	v.ReadJSON(it)
+	return it.End()
+}
+
+// ReadJSON reads JSON object into v matching fields by exact and then case-insensitive keys
+func (v *Item) ReadJSON(it *jessy.Iterator) {
+	it.ReadObject(func(key []byte) bool {
+		if name := zgo.B2S(key); !v.readJSONField(it, name) {
+			for _, k := range [...]string{"price", "qty", "sku"} {
+				if strings.EqualFold(k, name) {
+					v.readJSONField(it, k)
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+func (v *Item) readJSONField(it *jessy.Iterator, key string) bool {
+	switch key {
+	case "price":
+		it.ReadField(&v.Price, "precision=2")
+	case "qty":
+		if !it.ReadNull() {
+			v.Qty = it.ReadInt32()
+		}
+	case "sku":
+		if !it.ReadNull() {
+			v.SKU = it.ReadString()
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// AppendJSON appends JSON encoding of v, it implements jessy.AppendMarshaler
+func (v Account) AppendJSON(dst []byte) ([]byte, error) {
+	w := jessy.NewWriter(dst)
+	v.WriteJSON(&w)
+	return w.Result()
+}
+
+// WriteJSON writes v as JSON object
+func (v *Account) WriteJSON(w *jessy.Writer) {
+	w.ObjectStart()
+	w.Field("created", &v.Base.Created, "omitempty")
+	w.Key("id")
+	w.Int64(v.Base.ID)
+	w.Key("id")
+	w.Int64(int64(v.ID))
+	w.Key("limit")
+	if v.Limit == nil {
+		w.String("")
+	} else {
+		w.Quoted(func(q *jessy.Writer) { q.Float64(*v.Limit) })
+	}
+	w.Key("verified")
+	if v.Verified == nil {
+		w.String("")
+	} else {
+		w.Quoted(func(q *jessy.Writer) { q.Bool(*v.Verified) })
+	}
+	w.ObjectEnd()
+}
+
+// UnmarshalJSON decodes JSON object into v, it implements json.Unmarshaler
+func (v *Account) UnmarshalJSON(data []byte) error {
+	it := jessy.NewIterator(data)
+	v.ReadJSON(it)
+	return it.End()
+}
+
+// ReadJSON reads JSON object into v matching fields by exact and then case-insensitive keys
+func (v *Account) ReadJSON(it *jessy.Iterator) {
+	it.ReadObject(func(key []byte) bool {
+		if name := zgo.B2S(key); !v.readJSONField(it, name) {
+			for _, k := range [...]string{"created", "id", "limit", "verified"} {
+				if strings.EqualFold(k, name) {
+					v.readJSONField(it, k)
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+func (v *Account) readJSONField(it *jessy.Iterator, key string) bool {
+	switch key {
+	case "created":
+		it.ReadField(&v.Base.Created, "omitempty")
+	case "id":
+		if !it.ReadNull() {
+			v.ID = it.ReadInt()
+		}
+	case "limit":
+		if it.ReadNull() {
+			v.Limit = nil
+		} else {
+			if v.Limit == nil {
+				v.Limit = new(float64)
+			}
+			if !it.ReadNull() {
+				it.ReadQuoted(func(q *jessy.Iterator) {
+					if !q.ReadNull() {
+						*v.Limit = q.ReadFloat64()
+					}
+				})
+			}
+		}
+	case "verified":
+		if it.ReadNull() {
+			v.Verified = nil
+		} else {
+			if v.Verified == nil {
+				v.Verified = new(bool)
+			}
+			if !it.ReadNull() {
+				it.ReadQuoted(func(q *jessy.Iterator) {
+					if !q.ReadNull() {
+						*v.Verified = q.ReadBool()
+					}
+				})
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,836 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const jessyPath = "github.com/avpetkun/jessy-go"
+
+type kind int
+
+const (
+	kindOther kind = iota // encoded by cached encoder and decoded by Unmarshal
+	kindBool
+	kindString
+	kindInt
+	kindUint
+	kindFloat
+	kindBytes
+	kindSlice
+	kindArray
+	kindMap
+	kindPointer
+	kindStruct // type with generated methods
+)
+
+var basicTypes = map[string]typeInfo{
+	"bool":    {kind: kindBool},
+	"string":  {kind: kindString},
+	"int":     {kind: kindInt},
+	"int8":    {kind: kindInt, bits: 8},
+	"int16":   {kind: kindInt, bits: 16},
+	"int32":   {kind: kindInt, bits: 32},
+	"rune":    {kind: kindInt, bits: 32},
+	"int64":   {kind: kindInt, bits: 64},
+	"uint":    {kind: kindUint},
+	"uint8":   {kind: kindUint, bits: 8},
+	"byte":    {kind: kindUint, bits: 8},
+	"uint16":  {kind: kindUint, bits: 16},
+	"uint32":  {kind: kindUint, bits: 32},
+	"uint64":  {kind: kindUint, bits: 64},
+	"float32": {kind: kindFloat, bits: 32},
+	"float64": {kind: kindFloat, bits: 64},
+}
+
+// methods which make jessy encode type by them instead of its kind
+var marshalerMethods = []string{"AppendJSON", "MarshalJSON", "MarshalText"}
+
+// methods which make Unmarshal decode type by them instead of its kind
+var unmarshalerMethods = []string{"UnmarshalJSON", "UnmarshalText"}
+
+type typeInfo struct {
+	kind    kind
+	name    string            // Go expression of type
+	bits    int               // size of ints and floats, 0 for int and uint
+	elem    *typeInfo         // of slices, arrays, maps and pointers
+	key     *typeInfo         // of maps
+	imports map[string]string // packages used by name
+}
+
+type typeDecl struct {
+	spec    *ast.TypeSpec
+	imports map[string]string
+}
+
+// guard is embedded struct pointer which fields are accessed through
+type guard struct {
+	path string
+	typ  string
+}
+
+type field struct {
+	key     string
+	path    string // from receiver, e.g. v.Base.ID
+	guards  []guard
+	typ     *typeInfo
+	options string
+
+	omitEmpty bool
+	quoted    bool
+	extended  bool // has jessy options
+	tagged    bool // key is set by tag
+}
+
+type generator struct {
+	pkg     string
+	types   map[string]typeDecl
+	methods map[string]map[string]bool
+	gen     map[string]bool
+
+	imports map[string]string // of output
+	buf     bytes.Buffer
+}
+
+func newGenerator() *generator {
+	return &generator{
+		types:   make(map[string]typeDecl),
+		methods: make(map[string]map[string]bool),
+		gen:     make(map[string]bool),
+		imports: make(map[string]string),
+	}
+}
+
+func (g *generator) p(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+func (g *generator) hasMethod(typeName string, names ...string) bool {
+	for _, name := range names {
+		if g.methods[typeName][name] {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns name of type adding its packages to imports of output
+func (g *generator) typeName(t *typeInfo) string {
+	for name, path := range t.imports {
+		g.imports[name] = path
+	}
+	return t.name
+}
+
+func (g *generator) generate(typeNames []string) ([]byte, error) {
+	for _, name := range typeNames {
+		decl, ok := g.types[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s is not found in package %s", name, g.pkg)
+		}
+		if _, ok = decl.spec.Type.(*ast.StructType); !ok || decl.spec.Assign.IsValid() {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		if decl.spec.TypeParams != nil {
+			return nil, fmt.Errorf("generic type %s is not supported", name)
+		}
+		g.gen[name] = true
+	}
+
+	g.imports["jessy"] = jessyPath
+	for _, name := range typeNames {
+		decl := g.types[name]
+		fields, err := g.structFields(decl.spec.Type.(*ast.StructType), decl.imports, "v", nil, map[string]bool{name: true})
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %w", name, err)
+		}
+		g.writeMethods(name, fields)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by jessygen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg)
+	names := make([]string, 0, len(g.imports))
+	for name := range g.imports {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return g.imports[names[i]] < g.imports[names[j]]
+	})
+	// standard packages go first
+	sort.SliceStable(names, func(i, j int) bool {
+		return isStdPackage(g.imports[names[i]]) && !isStdPackage(g.imports[names[j]])
+	})
+	for i, name := range names {
+		importPath := g.imports[name]
+		if i > 0 && isStdPackage(g.imports[names[i-1]]) && !isStdPackage(importPath) {
+			out.WriteByte('\n')
+		}
+		if name == path.Base(importPath) || importPath == jessyPath {
+			fmt.Fprintf(&out, "\t%q\n", importPath)
+		} else {
+			fmt.Fprintf(&out, "\t%s %q\n", name, importPath)
+		}
+	}
+	out.WriteString(")\n\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+func isStdPackage(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// structFields returns fields in order of jessy encoder: fields of embedded
+// structs go first in order of declaration, then other fields sorted by keys
+func (g *generator) structFields(st *ast.StructType, imports map[string]string, prefix string, guards []guard, visiting map[string]bool) ([]field, error) {
+	var embedded, named []field
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			s, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(s).Get("json")
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if len(f.Names) == 0 {
+			fields, ok, err := g.embeddedFields(f.Type, name, prefix, guards, visiting)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				embedded = append(embedded, fields...)
+				continue
+			}
+			if fieldName := embeddedTypeName(f.Type); ast.IsExported(fieldName) && name != "-" {
+				named = append(named, g.newField(fieldName, name, options, f.Type, imports, prefix, guards))
+			}
+			continue
+		}
+		if name == "-" {
+			continue
+		}
+		for _, ident := range f.Names {
+			if ident.IsExported() {
+				named = append(named, g.newField(ident.Name, name, options, f.Type, imports, prefix, guards))
+			}
+		}
+	}
+	sort.SliceStable(named, func(i, j int) bool {
+		return `"`+named[i].key+`":` < `"`+named[j].key+`":`
+	})
+	return append(embedded, named...), nil
+}
+
+// embeddedFields returns fields of embedded struct if jessy flattens it
+func (g *generator) embeddedFields(expr ast.Expr, tagName, prefix string, guards []guard, visiting map[string]bool) ([]field, bool, error) {
+	star, isPointer := expr.(*ast.StarExpr)
+	if isPointer {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.SelectorExpr:
+		return nil, false, fmt.Errorf("embedded %s of other package can not be flattened, its fields are unknown", types.ExprString(t))
+	case *ast.Ident:
+		decl, ok := g.types[t.Name]
+		if !ok || decl.spec.Assign.IsValid() {
+			return nil, false, nil
+		}
+		st, ok := decl.spec.Type.(*ast.StructType)
+		if !ok {
+			return nil, false, nil
+		}
+		if g.gen[t.Name] || g.hasMethod(t.Name, "AppendJSON") {
+			return nil, false, fmt.Errorf("embedded %s has AppendJSON method, so jessy would not use AppendJSON of the outer type", t.Name)
+		}
+		if g.hasMethod(t.Name, marshalerMethods...) {
+			return nil, false, nil
+		}
+		if tagName == "-" {
+			return nil, true, nil
+		}
+		if visiting[t.Name] {
+			return nil, false, fmt.Errorf("recursive embedding of %s", t.Name)
+		}
+		visiting[t.Name] = true
+		defer delete(visiting, t.Name)
+
+		path := prefix + "." + t.Name
+		if isPointer {
+			guards = append(guards[:len(guards):len(guards)], guard{path: path, typ: t.Name})
+		}
+		fields, err := g.structFields(st, decl.imports, path, guards, visiting)
+		return fields, true, err
+	}
+	return nil, false, nil
+}
+
+func embeddedTypeName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+func (g *generator) newField(goName, key, options string, expr ast.Expr, imports map[string]string, prefix string, guards []guard) field {
+	tagged := key != ""
+	if !tagged {
+		key = goName
+	}
+	f := field{
+		key:     key,
+		path:    prefix + "." + goName,
+		guards:  guards,
+		typ:     g.resolve(expr, imports, make(map[string]bool)),
+		options: options,
+		tagged:  tagged,
+	}
+	for _, option := range strings.Split(options, ",") {
+		switch option {
+		case "":
+		case "omitempty":
+			f.omitEmpty = true
+		case "string":
+			f.quoted = true
+		default:
+			f.extended = true
+		}
+	}
+	return f
+}
+
+// resolve returns info of type how jessy encodes it
+func (g *generator) resolve(expr ast.Expr, imports map[string]string, visiting map[string]bool) *typeInfo {
+	t := &typeInfo{name: types.ExprString(expr)}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return g.resolve(e.X, imports, visiting)
+
+	case *ast.Ident:
+		decl, local := g.types[e.Name]
+		if !local {
+			if basic, ok := basicTypes[e.Name]; ok {
+				t.kind, t.bits = basic.kind, basic.bits
+			}
+			return t
+		}
+		if g.gen[e.Name] {
+			t.kind = kindStruct
+			return t
+		}
+		if decl.spec.TypeParams != nil || visiting[e.Name] ||
+			g.hasMethod(e.Name, marshalerMethods...) || g.hasMethod(e.Name, unmarshalerMethods...) {
+			return t
+		}
+		if _, ok := decl.spec.Type.(*ast.StructType); ok {
+			return t
+		}
+		visiting[e.Name] = true
+		u := g.resolve(decl.spec.Type, decl.imports, visiting)
+		delete(visiting, e.Name)
+		if decl.spec.Assign.IsValid() {
+			return u
+		}
+		if u.kind == kindPointer {
+			return t
+		}
+		u.name = e.Name
+		u.imports = nil
+		return u
+
+	case *ast.StarExpr:
+		elem := g.resolve(e.X, imports, visiting)
+		if elem.kind == kindOther {
+			return t
+		}
+		t.kind = kindPointer
+		t.elem = elem
+
+	case *ast.ArrayType:
+		elem := g.resolve(e.Elt, imports, visiting)
+		if e.Len != nil {
+			t.kind = kindArray
+		} else if elem.kind != kindUint || elem.bits != 8 {
+			t.kind = kindSlice
+		} else if elem.name == "byte" || elem.name == "uint8" {
+			t.kind = kindBytes
+		} else {
+			// named bytes are base64 too, but Writer.Bytes does not accept them
+			return t
+		}
+		t.elem = elem
+
+	case *ast.MapType:
+		key := g.resolve(e.Key, imports, visiting)
+		if key.kind != kindString {
+			return t
+		}
+		t.kind = kindMap
+		t.key = key
+		t.elem = g.resolve(e.Value, imports, visiting)
+
+	default:
+		return t
+	}
+	t.imports = exprImports(expr, imports)
+	return t
+}
+
+// exprImports returns packages used by type expression
+func exprImports(expr ast.Expr, imports map[string]string) map[string]string {
+	var used map[string]string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok && imports[pkg.Name] != "" {
+				if used == nil {
+					used = make(map[string]string)
+				}
+				used[pkg.Name] = imports[pkg.Name]
+			}
+		}
+		return true
+	})
+	return used
+}
+
+func (g *generator) writeMethods(name string, fields []field) {
+	g.p("// AppendJSON appends JSON encoding of v, it implements jessy.AppendMarshaler")
+	g.p("func (v %s) AppendJSON(dst []byte) ([]byte, error) {", name)
+	g.p("w := jessy.NewWriter(dst)")
+	g.p("v.WriteJSON(&w)")
+	g.p("return w.Result()")
+	g.p("}")
+	g.p("")
+	g.p("// WriteJSON writes v as JSON object")
+	g.p("func (v *%s) WriteJSON(w *jessy.Writer) {", name)
+	g.p("w.ObjectStart()")
+	for _, f := range fields {
+		g.writeField(f)
+	}
+	g.p("w.ObjectEnd()")
+	g.p("}")
+	g.p("")
+
+	var keys []string
+	var decodeFields []field
+	for i, f := range fields {
+		if dominantField(fields, f.key) == i {
+			keys = append(keys, strconv.Quote(f.key))
+			decodeFields = append(decodeFields, f)
+		}
+	}
+
+	g.p("// UnmarshalJSON decodes JSON object into v, it implements json.Unmarshaler")
+	g.p("func (v *%s) UnmarshalJSON(data []byte) error {", name)
+	g.p("it := jessy.NewIterator(data)")
+	g.p("v.ReadJSON(it)")
+	g.p("return it.End()")
+	g.p("}")
+	g.p("")
+	g.p("// ReadJSON reads JSON object into v matching fields by exact and then case-insensitive keys")
+	g.p("func (v *%s) ReadJSON(it *jessy.Iterator) {", name)
+	if len(keys) == 0 {
+		g.p("it.ReadObject(func([]byte) bool { return true })")
+		g.p("}")
+		g.p("")
+		return
+	}
+	g.imports["zgo"] = jessyPath + "/zgo"
+	g.imports["strings"] = "strings"
+	g.p("it.ReadObject(func(key []byte) bool {")
+	g.p("if name := zgo.B2S(key); !v.readJSONField(it, name) {")
+	g.p("for _, k := range [...]string{%s} {", strings.Join(keys, ", "))
+	g.p("if strings.EqualFold(k, name) {")
+	g.p("v.readJSONField(it, k)")
+	g.p("break")
+	g.p("}")
+	g.p("}")
+	g.p("}")
+	g.p("return true")
+	g.p("})")
+	g.p("}")
+	g.p("")
+	g.p("func (v *%s) readJSONField(it *jessy.Iterator, key string) bool {", name)
+	g.p("switch key {")
+	for _, f := range decodeFields {
+		g.p("case %q:", f.key)
+		g.readField(f)
+	}
+	g.p("default:")
+	g.p("return false")
+	g.p("}")
+	g.p("return true")
+	g.p("}")
+	g.p("")
+}
+
+// dominantField returns index of field decoded by key as Unmarshal does: the least nested
+// field hides others, then the tagged one, -1 is returned if fields are ambiguous
+func dominantField(fields []field, key string) int {
+	dominant, depth, ambiguous := -1, 0, false
+	for i, f := range fields {
+		if f.key != key {
+			continue
+		}
+		d := strings.Count(f.path, ".")
+		switch {
+		case dominant < 0 || d < depth:
+			dominant, depth, ambiguous = i, d, false
+		case d == depth:
+			if f.tagged == fields[dominant].tagged {
+				ambiguous = true
+			} else if f.tagged {
+				dominant, ambiguous = i, false
+			}
+		}
+	}
+	if ambiguous {
+		return -1
+	}
+	return dominant
+}
+
+func (g *generator) writeField(f field) {
+	var conds []string
+	for _, guard := range f.guards {
+		conds = append(conds, guard.path+" != nil")
+	}
+	fallback := f.extended || f.typ.kind == kindOther || (f.quoted && !quotable(f.typ))
+	// jessy writes nil pointers of string option as empty strings even if omitempty is set
+	omitEmpty := f.omitEmpty && !(f.quoted && f.typ.kind == kindPointer)
+	if omitEmpty && !fallback {
+		if c := emptyCheck(f.typ, f.path); c != "" {
+			conds = append(conds, c)
+		}
+	}
+	if len(conds) != 0 {
+		g.p("if %s {", strings.Join(conds, " && "))
+	}
+	switch {
+	case fallback:
+		g.p("w.Field(%q, &%s, %q)", f.key, f.path, f.options)
+	case f.quoted:
+		g.p("w.Key(%q)", f.key)
+		g.writeQuoted(f.path, f.typ, omitEmpty)
+	default:
+		g.p("w.Key(%q)", f.key)
+		g.writeValue(f.path, f.typ, 0, omitEmpty)
+	}
+	if len(conds) != 0 {
+		g.p("}")
+	}
+}
+
+// emptyCheck returns condition of value which is not omitted by jessy
+func emptyCheck(t *typeInfo, x string) string {
+	switch t.kind {
+	case kindBool:
+		return x
+	case kindString:
+		return x + ` != ""`
+	case kindInt, kindUint, kindFloat:
+		return x + " != 0"
+	case kindBytes, kindSlice:
+		return "len(" + x + ") != 0"
+	case kindMap, kindPointer:
+		return x + " != nil"
+	}
+	return ""
+}
+
+// deref returns expression of value of pointer x, parenthesized for indexing
+func deref(x string, elem *typeInfo) string {
+	switch elem.kind {
+	case kindSlice, kindArray, kindMap:
+		return "(*" + x + ")"
+	}
+	return "*" + x
+}
+
+// conv returns x converted to builtin type if it is named type
+func conv(to string, t *typeInfo, x string) string {
+	if t.name == to {
+		return x
+	}
+	return to + "(" + x + ")"
+}
+
+// writeMethod returns Writer method writing basic type and its argument type
+func writeMethod(t *typeInfo) (string, string) {
+	switch t.kind {
+	case kindBool:
+		return "Bool", "bool"
+	case kindString:
+		return "String", "string"
+	case kindInt:
+		return "Int64", "int64"
+	case kindUint:
+		return "Uint64", "uint64"
+	case kindFloat:
+		if t.bits == 32 {
+			return "Float32", "float32"
+		}
+		return "Float64", "float64"
+	}
+	return "", ""
+}
+
+// writeQuoted writes quotable value x of field with string option as jessy does:
+// numbers and bools are quoted, strings are not and nil pointers are empty strings
+func (g *generator) writeQuoted(x string, t *typeInfo, nonEmpty bool) {
+	switch t.kind {
+	case kindString:
+		g.writeValue(x, t, 0, nonEmpty)
+	case kindPointer:
+		if !nonEmpty {
+			g.p("if %s == nil {", x)
+			g.p(`w.String("")`)
+			g.p("} else {")
+		}
+		g.writeQuoted(deref(x, t.elem), t.elem, false)
+		if !nonEmpty {
+			g.p("}")
+		}
+	default:
+		method, typ := writeMethod(t)
+		g.p("w.Quoted(func(q *jessy.Writer) { q.%s(%s) })", method, conv(typ, t, x))
+	}
+}
+
+// writeValue writes value x, nonEmpty is set if x is checked by emptyCheck
+func (g *generator) writeValue(x string, t *typeInfo, depth int, nonEmpty bool) {
+	switch t.kind {
+	case kindBool, kindString, kindInt, kindUint, kindFloat:
+		method, typ := writeMethod(t)
+		g.p("w.%s(%s)", method, conv(typ, t, x))
+	case kindBytes:
+		if nonEmpty {
+			g.p("w.Bytes(%s)", x)
+			break
+		}
+		// jessy writes empty bytes as empty array
+		g.p("if len(%s) == 0 {", x)
+		g.p("w.ArrayStart()")
+		g.p("w.ArrayEnd()")
+		g.p("} else {")
+		g.p("w.Bytes(%s)", x)
+		g.p("}")
+	case kindSlice, kindArray:
+		i := fmt.Sprintf("i%d", depth)
+		g.p("w.ArrayStart()")
+		g.p("for %s := range %s {", i, x)
+		g.writeValue(x+"["+i+"]", t.elem, depth+1, false)
+		g.p("}")
+		g.p("w.ArrayEnd()")
+	case kindMap:
+		k, keys := fmt.Sprintf("k%d", depth), fmt.Sprintf("keys%d", depth)
+		g.imports["slices"] = "slices"
+		if !nonEmpty {
+			g.p("if %s == nil {", x)
+			g.p("w.Null()")
+			g.p("} else {")
+		}
+		g.p("%s := make([]%s, 0, len(%s))", keys, g.typeName(t.key), x)
+		g.p("for %s := range %s {", k, x)
+		g.p("%s = append(%s, %s)", keys, keys, k)
+		g.p("}")
+		g.p("slices.Sort(%s)", keys)
+		g.p("w.ObjectStart()")
+		g.p("for _, %s := range %s {", k, keys)
+		g.p("w.Key(%s)", conv("string", t.key, k))
+		elem := x + "[" + k + "]"
+		if t.elem.kind == kindStruct || t.elem.kind == kindOther {
+			// map values are not addressable
+			e := fmt.Sprintf("e%d", depth)
+			g.p("%s := %s", e, elem)
+			elem = e
+		}
+		g.writeValue(elem, t.elem, depth+1, false)
+		g.p("}")
+		g.p("w.ObjectEnd()")
+		if !nonEmpty {
+			g.p("}")
+		}
+	case kindPointer:
+		if !nonEmpty {
+			g.p("if %s == nil {", x)
+			g.p("w.Null()")
+			g.p("} else {")
+		}
+		if t.elem.kind == kindStruct {
+			g.p("%s.WriteJSON(w)", x)
+		} else {
+			g.writeValue(deref(x, t.elem), t.elem, depth, false)
+		}
+		if !nonEmpty {
+			g.p("}")
+		}
+	case kindStruct:
+		g.p("%s.WriteJSON(w)", x)
+	default:
+		g.p("w.Value(&%s)", x)
+	}
+}
+
+func (g *generator) readField(f field) {
+	for _, guard := range f.guards {
+		g.p("if %s == nil {", guard.path)
+		g.p("%s = new(%s)", guard.path, guard.typ)
+		g.p("}")
+	}
+	if f.extended || f.typ.kind == kindOther || (f.quoted && !quotable(f.typ)) {
+		g.p("it.ReadField(&%s, %q)", f.path, f.options)
+		return
+	}
+	g.readValue("it", f.path, f.typ, f.quoted, 0)
+}
+
+// quotable reports whether string option is applied to type by Unmarshal
+func quotable(t *typeInfo) bool {
+	switch t.kind {
+	case kindBool, kindString, kindInt, kindUint, kindFloat:
+		return true
+	case kindPointer:
+		return quotable(t.elem)
+	}
+	return false
+}
+
+// readMethod returns Iterator method reading basic type and its result type
+func readMethod(t *typeInfo) (string, string) {
+	switch t.kind {
+	case kindBool:
+		return "ReadBool", "bool"
+	case kindString:
+		return "ReadString", "string"
+	case kindInt, kindUint:
+		typ := "int"
+		if t.kind == kindUint {
+			typ = "uint"
+		}
+		if t.bits != 0 {
+			typ += strconv.Itoa(t.bits)
+		}
+		return "Read" + strings.ToUpper(typ[:1]) + typ[1:], typ
+	case kindFloat:
+		typ := "float" + strconv.Itoa(t.bits)
+		return "ReadFloat" + strconv.Itoa(t.bits), typ
+	}
+	return "", ""
+}
+
+// readValue reads value of iterator it into x, null does not change values
+// of basic types and sets nil to pointers, slices and maps as Unmarshal does
+func (g *generator) readValue(it, x string, t *typeInfo, quoted bool, depth int) {
+	switch t.kind {
+	case kindBool, kindString, kindInt, kindUint, kindFloat:
+		method, typ := readMethod(t)
+		read := it + "." + method + "()"
+		if t.name != typ {
+			read = t.name + "(" + read + ")"
+		}
+		g.p("if !%s.ReadNull() {", it)
+		if quoted {
+			g.p("%s.ReadQuoted(func(q *jessy.Iterator) {", it)
+			g.readValue("q", x, t, false, depth)
+			g.p("})")
+		} else {
+			g.p("%s = %s", x, read)
+		}
+		g.p("}")
+	case kindBytes:
+		g.p("if %s.ReadNull() {", it)
+		g.p("%s = nil", x)
+		g.p("} else {")
+		if t.name == "[]byte" {
+			g.p("%s = %s.ReadBytes()", x, it)
+		} else {
+			g.p("%s = %s(%s.ReadBytes())", x, t.name, it)
+		}
+		g.p("}")
+	case kindSlice:
+		e := fmt.Sprintf("e%d", depth)
+		g.p("if %s.ReadNull() {", it)
+		g.p("%s = nil", x)
+		g.p("} else {")
+		g.p("%s = %s[:0]", x, x)
+		g.p("if %s == nil {", x)
+		g.p("%s = %s{}", x, g.typeName(t))
+		g.p("}")
+		g.p("%s.ReadArray(func() bool {", it)
+		g.p("var %s %s", e, g.typeName(t.elem))
+		g.readValue(it, e, t.elem, false, depth+1)
+		g.p("%s = append(%s, %s)", x, x, e)
+		g.p("return true")
+		g.p("})")
+		g.p("}")
+	case kindArray:
+		i := fmt.Sprintf("i%d", depth)
+		g.p("if !%s.ReadNull() {", it)
+		g.p("%s := 0", i)
+		g.p("%s.ReadArray(func() bool {", it)
+		g.p("if %s < len(%s) {", i, x)
+		g.readValue(it, x+"["+i+"]", t.elem, false, depth+1)
+		g.p("}")
+		g.p("%s++", i)
+		g.p("return true")
+		g.p("})")
+		g.p("if %s < len(%s) {", i, x)
+		g.p("clear(%s[%s:])", x, i)
+		g.p("}")
+		g.p("}")
+	case kindMap:
+		k, e := fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth)
+		g.p("if %s.ReadNull() {", it)
+		g.p("%s = nil", x)
+		g.p("} else {")
+		g.p("if %s == nil {", x)
+		g.p("%s = make(%s)", x, g.typeName(t))
+		g.p("}")
+		g.p("%s.ReadObject(func(key []byte) bool {", it)
+		g.p("%s := %s(key)", k, g.typeName(t.key))
+		g.p("var %s %s", e, g.typeName(t.elem))
+		g.readValue(it, e, t.elem, false, depth+1)
+		g.p("%s[%s] = %s", x, k, e)
+		g.p("return true")
+		g.p("})")
+		g.p("}")
+	case kindPointer:
+		g.p("if %s.ReadNull() {", it)
+		g.p("%s = nil", x)
+		g.p("} else {")
+		g.p("if %s == nil {", x)
+		g.p("%s = new(%s)", x, g.typeName(t.elem))
+		g.p("}")
+		if t.elem.kind == kindStruct {
+			g.p("%s.ReadJSON(%s)", x, it)
+		} else {
+			g.readValue(it, deref(x, t.elem), t.elem, quoted, depth)
+		}
+		g.p("}")
+	case kindStruct:
+		g.p("%s.ReadJSON(%s)", x, it)
+	default:
+		g.p("%s.ReadValue(&%s)", it, x)
+	}
+}
@@ -0,0 +1,167 @@
+// Jessygen generates AppendJSON and UnmarshalJSON methods of struct types,
+// which encode and decode them as jessy does by reflection, but without it.
+// Add a directive to a file of the package and run go generate:
+//
+//	//go:generate go run github.com/avpetkun/jessy-go/cmd/jessygen -type=User,Order
+//
+// For each type T the methods are
+//   - AppendJSON and WriteJSON(*jessy.Writer) writing fields sorted by keys,
+//     omitting empty values of omitempty fields, quoting values of string fields
+//     and flattening fields of embedded structs
+//   - UnmarshalJSON and ReadJSON(*jessy.Iterator) reading fields by exact
+//     and then case-insensitive keys, a key of several fields is read into
+//     the least nested or tagged one by Go rules of embedding as Unmarshal does
+//
+// Fields of types without generated methods and of types of other packages,
+// interfaces and fields with extended tag options (precision=n, bytes=hex...)
+// are encoded by Writer.Field and decoded by Unmarshal
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of struct type names, required")
+	output := flag.String("output", "", "output file name, default <type>_jessy.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jessygen -type T[,T...] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("jessygen: ")
+
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	if *output == "" {
+		*output = strings.ToLower(types[0]) + "_jessy.go"
+	}
+	outputPath := filepath.Join(dir, *output)
+
+	g, err := loadPackage(dir, outputPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := g.generate(types)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(outputPath, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadPackage parses non-test go files of dir except of output file
+func loadPackage(dir, outputPath string) (*generator, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	g := newGenerator()
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if filepath.Clean(path) == filepath.Clean(outputPath) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if g.pkg == "" {
+			g.pkg = file.Name.Name
+		} else if g.pkg != file.Name.Name {
+			return nil, fmt.Errorf("files of packages %s and %s in %s", g.pkg, file.Name.Name, dir)
+		}
+		g.addFile(file)
+	}
+	if g.pkg == "" {
+		return nil, fmt.Errorf("no go files in %s", dir)
+	}
+	return g, nil
+}
+
+// addFile collects type declarations, methods and imports of file
+func (g *generator) addFile(file *ast.File) {
+	imports := fileImports(file)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				g.types[spec.Name.Name] = typeDecl{spec: spec, imports: imports}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if index, ok := recv.(*ast.IndexExpr); ok {
+				recv = index.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				if g.methods[ident.Name] == nil {
+					g.methods[ident.Name] = make(map[string]bool)
+				}
+				g.methods[ident.Name][decl.Name.Name] = true
+			}
+		}
+	}
+}
+
+// fileImports maps names of imported packages to their paths
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path := strings.Trim(spec.Path.Value, "`\"")
+		if spec.Name != nil {
+			imports[spec.Name.Name] = path
+		} else {
+			imports[guessPackageName(path)] = path
+		}
+	}
+	return imports
+}
+
+// guessPackageName returns name of package by common conventions of paths,
+// e.g. gopkg.in/yaml.v3 is yaml and github.com/user/go-lib is lib
+func guessPackageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestGenerate(t *testing.T) {
+	output := filepath.Join("example", "user_jessy.go")
+	g, err := loadPackage("example", output)
+	require.NoError(t, err)
+	src, err := g.generate([]string{"User", "Address", "Item", "Account"})
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(src))
+
+	for typeName, errText := range map[string]string{
+		"Status":  "is not a struct",
+		"Missing": "is not found",
+	} {
+		g, err = loadPackage("example", output)
+		require.NoError(t, err)
+		_, err = g.generate([]string{typeName})
+		if err == nil || !strings.Contains(err.Error(), errText) {
+			t.Fatalf("%s: expected error %q, actual %v", typeName, errText, err)
+		}
+	}
+
+	// AppendJSON of embedded type hides AppendJSON of outer type from jessy
+	g, err = loadPackage("example", output)
+	require.NoError(t, err)
+	_, err = g.generate([]string{"User", "Base"})
+	if err == nil || !strings.Contains(err.Error(), "embedded Base") {
+		t.Fatalf("expected error of embedded type, actual %v", err)
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	for path, name := range map[string]string{
+		"time":                         "time",
+		"gopkg.in/yaml.v3":             "yaml",
+		"github.com/user/go-lib":       "lib",
+		"github.com/user/lib/v2":       "lib",
+		"github.com/avpetkun/jessy-go": "jessy",
+	} {
+		require.Equal(t, name, guessPackageName(path))
+	}
+}
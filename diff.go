@@ -0,0 +1,152 @@
+package jessy
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Change is a difference of two values at JSON pointer Path with their JSON,
+// Old is nil for added values and New is nil for removed ones
+type Change struct {
+	Path string
+	Old  []byte
+	New  []byte
+}
+
+// String returns change as a line of text, e.g. `~ /name: "ann" -> "bob"`
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "(root)"
+	}
+	switch {
+	case c.Old == nil:
+		return "+ " + path + ": " + string(c.New)
+	case c.New == nil:
+		return "- " + path + ": " + string(c.Old)
+	}
+	return "~ " + path + ": " + string(c.Old) + " -> " + string(c.New)
+}
+
+// Diff returns changes turning a into b as they are encoded by Marshal.
+// Structs are walked by fields of cached encoders, so fields are named by json tags
+// and omitted fields are added or removed. Marshalers and other values are compared
+// by their JSON, so documents passed as RawMessage are compared by members and elements.
+// Walking stops at the first value failing to encode and its error is returned,
+// values nested too deep, as cyclic ones are, fail too
+func Diff(a, b any) ([]Change, error) {
+	var e pathEval
+	na := e.goValue(reflect.TypeFor[any](), unsafe.Pointer(&a), EncodeStandard, 0)
+	nb := e.goValue(reflect.TypeFor[any](), unsafe.Pointer(&b), EncodeStandard, 0)
+	if e.err != nil {
+		return nil, e.err
+	}
+	changes := e.diff(nil, nil, diffNode(na), diffNode(nb))
+	if e.err != nil {
+		return nil, e.err
+	}
+	return changes, nil
+}
+
+func diffNode(n pathNode) pathNode {
+	if n == nil {
+		return rawNode("null")
+	}
+	return n
+}
+
+// DiffPatch returns changes as JSON Patch (RFC 6902) of add, remove and replace operations
+func DiffPatch(changes []Change) []byte {
+	w := NewWriter(nil)
+	w.ArrayStart()
+	for _, c := range changes {
+		w.ObjectStart()
+		w.Key("op")
+		switch {
+		case c.Old == nil:
+			w.String("add")
+		case c.New == nil:
+			w.String("remove")
+		default:
+			w.String("replace")
+		}
+		w.Key("path")
+		w.String(c.Path)
+		if c.New != nil {
+			w.Key("value")
+			w.Raw(c.New)
+		}
+		w.ObjectEnd()
+	}
+	w.ArrayEnd()
+	patch, _ := w.Result()
+	return patch
+}
+
+// DiffText returns changes as lines of text
+func DiffText(changes []Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// diff appends changes of nodes at path, members of objects are compared by keys,
+// extra elements of arrays are removed from the end
+func (e *pathEval) diff(changes []Change, path []byte, a, b pathNode) []Change {
+	if e.err != nil {
+		return changes
+	}
+	switch ka, kb := a.kind(), b.kind(); {
+	case ka == KindObject && kb == KindObject:
+		keysA, membersA := e.diffMembers(a)
+		keysB, membersB := e.diffMembers(b)
+		for _, key := range keysA {
+			if vb, ok := membersB[key]; ok {
+				changes = e.diff(changes, appendPointerToken(path, key), membersA[key], vb)
+			} else {
+				changes = append(changes, Change{Path: string(appendPointerToken(path, key)), Old: e.json(membersA[key])})
+			}
+		}
+		for _, key := range keysB {
+			if _, ok := membersA[key]; !ok {
+				changes = append(changes, Change{Path: string(appendPointerToken(path, key)), New: e.json(membersB[key])})
+			}
+		}
+	case ka == KindArray && kb == KindArray:
+		x, y := e.items(a), e.items(b)
+		for i := range min(len(x), len(y)) {
+			changes = e.diff(changes, strconv.AppendInt(append(path, '/'), int64(i), 10), x[i], y[i])
+		}
+		for i := len(x) - 1; i >= len(y); i-- {
+			changes = append(changes, Change{Path: string(strconv.AppendInt(append(path, '/'), int64(i), 10)), Old: e.json(x[i])})
+		}
+		for i := len(x); i < len(y); i++ {
+			changes = append(changes, Change{Path: string(strconv.AppendInt(append(path, '/'), int64(i), 10)), New: e.json(y[i])})
+		}
+	default:
+		ja, jb := e.json(a), e.json(b)
+		if !pathEqual(ja, jb) {
+			changes = append(changes, Change{Path: string(path), Old: ja, New: jb})
+		}
+	}
+	return changes
+}
+
+// diffMembers returns keys of object node in order and their values
+func (e *pathEval) diffMembers(n pathNode) (keys []string, members map[string]pathNode) {
+	members = make(map[string]pathNode)
+	n.members(e, func(key []byte, v pathNode) bool {
+		k := string(key)
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = v
+		return true
+	})
+	return
+}
@@ -0,0 +1,137 @@
+package jessy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+type diffConfig struct {
+	*diffBase
+	Name    string            `json:"name"`
+	Port    int               `json:"port,omitempty"`
+	Hosts   []string          `json:"hosts"`
+	Labels  map[string]string `json:"labels"`
+	Limits  *diffLimits       `json:"limits,omitempty"`
+	Started time.Time         `json:"started"`
+	Extra   RawMessage        `json:"extra"`
+	Secret  string            `json:"-"`
+}
+
+type diffBase struct {
+	Version int `json:"version"`
+}
+
+type diffLimits struct {
+	CPU    float64 `json:"cpu"`
+	Memory int     `json:"memory,string"`
+}
+
+func TestDiff(t *testing.T) {
+	a := diffConfig{
+		diffBase: &diffBase{Version: 1},
+		Name:     "api",
+		Hosts:    []string{"a", "b", "c"},
+		Labels:   map[string]string{"env": "dev", "team": "x"},
+		Limits:   &diffLimits{CPU: 1, Memory: 512},
+		Started:  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Extra:    RawMessage(`{"a": [1, 2], "b": true}`),
+		Secret:   "a",
+	}
+	b := a
+	b.diffBase = &diffBase{Version: 2}
+	b.Port = 80
+	b.Hosts = []string{"a", "d"}
+	b.Labels = map[string]string{"env": "prod", "a/b": "y"}
+	b.Limits = &diffLimits{CPU: 1, Memory: 1024}
+	b.Started = a.Started.Add(time.Hour)
+	b.Extra = RawMessage(`{"b": true, "a": [1]}`)
+	b.Secret = "b"
+
+	changes, err := Diff(a, b)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Path: "/version", Old: []byte(`1`), New: []byte(`2`)},
+		{Path: "/extra/a/1", Old: []byte(`2`)},
+		{Path: "/hosts/1", Old: []byte(`"b"`), New: []byte(`"d"`)},
+		{Path: "/hosts/2", Old: []byte(`"c"`)},
+		{Path: "/labels/env", Old: []byte(`"dev"`), New: []byte(`"prod"`)},
+		{Path: "/labels/team", Old: []byte(`"x"`)},
+		{Path: "/labels/a~1b", New: []byte(`"y"`)},
+		{Path: "/limits/memory", Old: []byte(`"512"`), New: []byte(`"1024"`)},
+		{Path: "/started", Old: []byte(`"2024-01-02T00:00:00Z"`), New: []byte(`"2024-01-02T01:00:00Z"`)},
+		{Path: "/port", New: []byte(`80`)},
+	}, changes)
+
+	require.Equal(t, `~ /version: 1 -> 2
+- /extra/a/1: 2
+~ /hosts/1: "b" -> "d"
+- /hosts/2: "c"
+~ /labels/env: "dev" -> "prod"
+- /labels/team: "x"
++ /labels/a~1b: "y"
+~ /limits/memory: "512" -> "1024"
+~ /started: "2024-01-02T00:00:00Z" -> "2024-01-02T01:00:00Z"
++ /port: 80
+`, DiffText(changes))
+
+	// patch of changes turns JSON of a into JSON of b
+	ja, err := Marshal(a)
+	require.NoError(t, err)
+	jb, err := Marshal(b)
+	require.NoError(t, err)
+	result, err := ApplyPatch(ja, DiffPatch(changes))
+	require.NoError(t, err)
+	require.Equal(t, true, pathEqual(result, jb))
+
+	for _, c := range []struct {
+		a, b    any
+		changes []Change
+	}{
+		{a, a, nil},
+		{nil, nil, nil},
+		{nil, map[string]int{"a": 1}, []Change{{Path: "", Old: []byte(`null`), New: []byte(`{"a":1}`)}}},
+		{map[string]any{"a": 1}, map[string]any{"a": 1.5}, []Change{{Path: "/a", Old: []byte(`1`), New: []byte(`1.5`)}}},
+	} {
+		changes, err := Diff(c.a, c.b)
+		require.NoError(t, err)
+		require.Equal(t, c.changes, changes)
+	}
+
+	// values failing to encode are not reported as equal or null
+	type withFunc struct {
+		F  int
+		Fn func() `json:",omitempty"`
+	}
+	for _, c := range [][2]any{
+		{withFunc{F: 2}, withFunc{F: 1, Fn: func() {}}},
+		{map[string]float64{"a": 1}, map[string]float64{"a": math.NaN()}},
+		{[]any{1, math.Inf(1)}, []any{1}},
+		{func() {}, 1},
+		{1, make(chan int)},
+	} {
+		changes, err := Diff(c[0], c[1])
+		require.NotEqual(t, nil, err)
+		require.Equal(t, []Change(nil), changes)
+	}
+
+	// cyclic values fail instead of endless comparing
+	type node struct {
+		Name string
+		Next *node
+	}
+	x, y := &node{Name: "a"}, &node{Name: "a"}
+	x.Next, y.Next = x, y
+	changes, err = Diff(x, y)
+	require.Equal(t, errPathTooDeep, err)
+	require.Equal(t, []Change(nil), changes)
+
+	require.Equal(t, "~ (root): 1 -> 2", Change{Old: []byte(`1`), New: []byte(`2`)}.String())
+	require.Equal(t, `[{"op":"remove","path":"/a"},{"op":"add","path":"/b","value":[]}]`, string(DiffPatch([]Change{
+		{Path: "/a", Old: []byte(`1`)},
+		{Path: "/b", New: []byte(`[]`)},
+	})))
+	require.Equal(t, `[]`, string(DiffPatch(nil)))
+}
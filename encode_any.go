@@ -26,6 +26,9 @@ func MarshalPrecacheFor[T any](flags Flags) {
 }
 
 func encodeAny(dst []byte, value any, flags Flags) ([]byte, error) {
+	if flags.Has(Colorize) {
+		return encodeColorized(dst, value, flags.Exclude(Colorize))
+	}
 	eface := zgo.UnpackEface(value)
 	if eface.Type == nil {
 		return append(dst, 'n', 'u', 'l', 'l'), nil
@@ -36,23 +39,44 @@ func encodeAny(dst []byte, value any, flags Flags) ([]byte, error) {
 	return dst, err
 }
 
-var encodersTypesCache [encodeFlagsLen]sync.Map
+type encoderCacheKey struct {
+	typ    *zgo.Type
+	flags  Flags
+	indent uint32
+}
+
+var encodersTypesCache sync.Map
 
 func ResetEncodersCache() {
-	for i := range encodersTypesCache {
-		encodersTypesCache[i] = sync.Map{}
-	}
+	encodersTypesCache = sync.Map{}
+	streamEncodersCache = sync.Map{}
+	pathFieldsCache = sync.Map{}
 }
 
 func getTypeEncoder(typ *zgo.Type, flags Flags) UnsafeEncoder {
-	if val, ok := encodersTypesCache[flags].Load(typ); ok {
+	return getTypeEncoderIndent(typ, flags, 0)
+}
+
+// getTypeEncoderIndent returns encoder of value nested into pretty output at indent level
+func getTypeEncoderIndent(typ *zgo.Type, flags Flags, indent uint32) UnsafeEncoder {
+	key := encoderCacheKey{typ, flags, indent}
+	if val, ok := encodersTypesCache.Load(key); ok {
 		return val.(UnsafeEncoder)
 	}
-	encoder := createTypeEncoder(0, 0, flags, typ.Native(), typ.IfaceIndir(), false)
-	encodersTypesCache[flags].Store(typ, encoder)
+	encoder := createTypeEncoder(0, indent, flags, typ.Native(), typ.IfaceIndir(), false)
+	encodersTypesCache.Store(key, encoder)
 	return encoder
 }
 
+// encodeAt encodes value of type t at ptr as field of struct with flags
+func encodeAt(dst []byte, t reflect.Type, flags Flags, ptr unsafe.Pointer) ([]byte, error) {
+	typ := zgo.TypeFromRType(t)
+	if !typ.IfaceIndir() {
+		ptr = *(*unsafe.Pointer)(ptr)
+	}
+	return getTypeEncoder(typ, flags)(dst, ptr)
+}
+
 func nopEncoder(dst []byte, v unsafe.Pointer) ([]byte, error) {
 	return dst, nil
 }
@@ -62,8 +86,8 @@ func nullEncoder(dst []byte, v unsafe.Pointer) ([]byte, error) {
 }
 
 func createItemTypeEncoder(deep, indent uint32, flags Flags, t reflect.Type) UnsafeEncoder {
-	ifaceIndir := t.Kind() == reflect.Pointer || zgo.RTypeIfaceIndir(t)
-	return createTypeEncoder(deep, indent, flags.Exclude(OmitEmpty), t, ifaceIndir, false)
+	// items of slices, arrays and maps are always accessed by pointer to their slot
+	return createTypeEncoder(deep, indent, flags.Exclude(OmitEmpty), t, true, false)
 }
 
 func createTypeEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir, embedded bool) UnsafeEncoder {
@@ -80,20 +104,29 @@ func createTypeEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIn
 	if t == timeType {
 		return timeEncoder(flags)
 	}
-	if t == typeBigInt {
+	switch t {
+	case typeBigInt:
 		return bigIntEncoder(flags)
+	case typeBigFloat:
+		return bigFloatEncoder(flags)
+	case typeBigRat:
+		return bigRatEncoder(flags)
+	}
+
+	if tReallyImplements(t, typeOrderedMapEncoding) {
+		return reflect.Zero(t).Interface().(orderedMapEncoding).orderedMapEncoder(deep, indent, flags)
 	}
 
 	tp := reflect.PointerTo(t)
 	switch {
 	case tReallyImplements(t, typeAppendMarshaler):
-		return appendMarshalerEncoder(t, flags)
+		return appendMarshalerEncoder(indent, t, flags)
 	case tReallyImplements(tp, typeAppendMarshaler):
-		return appendMarshalerEncoder(tp, flags)
+		return appendMarshalerEncoder(indent, tp, flags)
 	case tReallyImplements(t, typeMarshaler):
-		return marshalerEncoder(t, flags)
+		return marshalerEncoder(indent, t, flags)
 	case tReallyImplements(tp, typeMarshaler):
-		return marshalerEncoder(tp, flags)
+		return marshalerEncoder(indent, tp, flags)
 	case tReallyImplements(t, typeAppendTextMarshaler):
 		return appendTextMarshalerEncoder(t, flags)
 	case tReallyImplements(tp, typeAppendTextMarshaler):
@@ -114,9 +147,9 @@ func createTypeEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIn
 	case reflect.Slice:
 		return sliceEncoder(deep, indent, t, flags)
 	case reflect.Array:
-		return arrayEncoder(deep, indent, t, flags)
+		return arrayEncoder(deep, indent, t, flags, ifaceIndir)
 	case reflect.Interface:
-		return interfaceEncoder(flags)
+		return interfaceEncoder(indent, flags)
 
 	case reflect.Bool:
 		return boolEncoder(flags)
@@ -148,9 +181,24 @@ func createTypeEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIn
 		return complex64Encoder(flags)
 	case reflect.Complex128:
 		return complex128Encoder(flags)
+
+	case reflect.Func, reflect.Chan:
+		if seq := newSeqEncoding(t, flags); seq != nil {
+			return seqEncoder(deep, indent, t, flags, ifaceIndir, seq)
+		}
 	}
 
-	return nopEncoder
+	if flags.Has(SkipUnsupported) {
+		return nopEncoder
+	}
+	return unsupportedTypeEncoder(t)
+}
+
+func unsupportedTypeEncoder(t reflect.Type) UnsafeEncoder {
+	err := &UnsupportedTypeError{Type: t}
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		return dst, err
+	}
 }
 
 func pointerEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir, embedded bool) UnsafeEncoder {
@@ -187,12 +235,15 @@ func pointerEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir
 	}
 }
 
-func interfaceEncoder(flags Flags) UnsafeEncoder {
+func interfaceEncoder(indent uint32, flags Flags) UnsafeEncoder {
+	if !flags.Has(PrettySpaces) {
+		indent = 0
+	}
 	return func(dst []byte, value unsafe.Pointer) ([]byte, error) {
 		eface := (*zgo.EmptyInterface)(value)
 		if eface.Type == nil {
 			return append(dst, 'n', 'u', 'l', 'l'), nil
 		}
-		return getTypeEncoder(eface.Type, flags)(dst, eface.Data)
+		return getTypeEncoderIndent(eface.Type, flags, indent)(dst, eface.Data)
 	}
 }
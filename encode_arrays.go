@@ -1,6 +1,7 @@
 package jessy
 
 import (
+	"encoding/base64"
 	"reflect"
 	"unsafe"
 
@@ -10,8 +11,8 @@ import (
 
 func sliceEncoder(deep, indent uint32, t reflect.Type, flags Flags) UnsafeEncoder {
 	elem := t.Elem()
-	if elem.Kind() == reflect.Uint8 && !tImplementsAny(elem) {
-		return sliceBase64Encoder(flags)
+	if elem.Kind() == reflect.Uint8 && !tImplementsAny(elem) && !flags.Has(BytesArray) {
+		return sliceBytesEncoder(flags)
 	}
 
 	prettySpaces := flags.Has(PrettySpaces)
@@ -21,8 +22,7 @@ func sliceEncoder(deep, indent uint32, t reflect.Type, flags Flags) UnsafeEncode
 	elemEncoder := createItemTypeEncoder(deep, indent+1, flags, elem)
 
 	if prettySpaces {
-		deepSpaces0 := getIndent(indent)
-		deepSpaces1 := getIndent(indent + 1)
+		encodeItems := prettyItemsEncoder(indent, flags, elemEncoder, elemSize)
 		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
 			h := (*zgo.Slice)(v)
 			if h == nil || h.Len == 0 {
@@ -32,30 +32,7 @@ func sliceEncoder(deep, indent uint32, t reflect.Type, flags Flags) UnsafeEncode
 				dst = append(dst, '[', ']')
 				return dst, nil
 			}
-			dst = append(dst, '[', '\n')
-			var err error
-			for i := range h.Len {
-				dst = append(dst, deepSpaces1...)
-				dstLen := len(dst)
-				dst, err = elemEncoder(dst, unsafe.Add(h.Data, elemSize*i))
-				if err != nil {
-					return dst, err
-				}
-				if len(dst) == dstLen {
-					dst = dst[:dstLen-1-int(indent)]
-				} else {
-					dst = append(dst, ',', '\n')
-				}
-			}
-
-			if i := len(dst) - 2; dst[i] == ',' {
-				dst = dst[:i]
-			}
-			dst = append(dst, '\n')
-			dst = append(dst, deepSpaces0...)
-			dst = append(dst, ']')
-
-			return dst, nil
+			return encodeItems(dst, h.Data, h.Len)
 		}
 	}
 
@@ -91,8 +68,48 @@ func sliceEncoder(deep, indent uint32, t reflect.Type, flags Flags) UnsafeEncode
 	}
 }
 
-func sliceBase64Encoder(flags Flags) UnsafeEncoder {
+// prettyItemsEncoder encodes pretty items of slice or array
+func prettyItemsEncoder(indent uint32, flags Flags, elemEncoder UnsafeEncoder, elemSize uint) func(dst []byte, data unsafe.Pointer, count uint) ([]byte, error) {
+	deepSpaces0 := getIndent(flags, indent)
+	deepSpaces1 := getIndent(flags, indent+1)
+	width := getIndentStyle(flags).width
+	return func(dst []byte, data unsafe.Pointer, count uint) ([]byte, error) {
+		start := len(dst)
+		dst = append(dst, '[', '\n')
+		dstInitLen := len(dst)
+		var err error
+		for i := range count {
+			itemIndex := len(dst)
+			dst = append(dst, deepSpaces1...)
+			dstLen := len(dst)
+			dst, err = elemEncoder(dst, unsafe.Add(data, elemSize*i))
+			if err != nil {
+				return dst, err
+			}
+			if len(dst) == dstLen {
+				dst = dst[:itemIndex]
+			} else {
+				dst = append(dst, ',', '\n')
+			}
+		}
+		if i := len(dst); i == dstInitLen {
+			dst[i-1] = ']'
+			return dst, nil
+		}
+		dst = dst[:len(dst)-2]
+		dst = append(dst, '\n')
+		dst = append(dst, deepSpaces0...)
+		dst = append(dst, ']')
+		if width != 0 {
+			dst = zstr.CollapseIndent(dst, start, width, len(deepSpaces1), len(deepSpaces0))
+		}
+		return dst, nil
+	}
+}
+
+func sliceBytesEncoder(flags Flags) UnsafeEncoder {
 	omitEmpty := flags.Has(OmitEmpty)
+	appendBytes := getBytesAppender(flags)
 	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
 		data := *(*[]byte)(v)
 		if len(data) == 0 {
@@ -101,16 +118,57 @@ func sliceBase64Encoder(flags Flags) UnsafeEncoder {
 			}
 			return append(dst, '[', ']'), nil
 		}
-		return zstr.AppendBase64String(dst, data), nil
+		return appendBytes(dst, data), nil
+	}
+}
+
+func arrayBytesEncoder(arrayLen uint, flags Flags) UnsafeEncoder {
+	appendBytes := getBytesAppender(flags)
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		return appendBytes(dst, zgo.NewSliceBytes(v, arrayLen, arrayLen)), nil
 	}
 }
 
-func arrayEncoder(deep, indent uint32, t reflect.Type, flags Flags) UnsafeEncoder {
+func getBytesAppender(flags Flags) func(dst, data []byte) []byte {
+	switch {
+	case flags.Has(BytesHex):
+		return zstr.AppendQuotedHex
+	case flags.Has(BytesBase64URL):
+		return func(dst, data []byte) []byte {
+			return zstr.AppendBase64StringEncoding(dst, data, base64.URLEncoding)
+		}
+	case flags.Has(BytesBase64Raw):
+		return func(dst, data []byte) []byte {
+			return zstr.AppendBase64StringEncoding(dst, data, base64.RawStdEncoding)
+		}
+	}
+	return zstr.AppendBase64String
+}
+
+func arrayEncoder(deep, indent uint32, t reflect.Type, flags Flags, ifaceIndir bool) UnsafeEncoder {
 	arrayLen := uint(t.Len())
 	elem := t.Elem()
+	if elem.Kind() == reflect.Uint8 && !tImplementsAny(elem) && flags.Has(bytesStringFlags) {
+		return arrayBytesEncoder(arrayLen, flags)
+	}
 
 	elemSize := uint(elem.Size())
-	elemEncoder := createItemTypeEncoder(deep, indent, flags, elem)
+
+	if flags.Has(PrettySpaces) {
+		if arrayLen == 0 {
+			return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+				return append(dst, '[', ']'), nil
+			}
+		}
+		elemEncoder := createTypeEncoder(deep, indent+1, flags.Exclude(OmitEmpty), elem, ifaceIndir, false)
+		encodeItems := prettyItemsEncoder(indent, flags, elemEncoder, elemSize)
+		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+			return encodeItems(dst, v, arrayLen)
+		}
+	}
+
+	// array items are stored inline like struct fields
+	elemEncoder := createTypeEncoder(deep, indent, flags.Exclude(OmitEmpty), elem, ifaceIndir, false)
 
 	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
 		dst = append(dst, '[')
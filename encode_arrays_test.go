@@ -0,0 +1,87 @@
+package jessy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestMarshalBytesFormat(t *testing.T) {
+	type Hashes struct {
+		Std    []byte   `json:"std"`
+		URL    []byte   `json:"url,bytes=base64url"`
+		Raw    []byte   `json:"raw,bytes=base64raw"`
+		Hex    []byte   `json:"hex,bytes=hex"`
+		Array  []byte   `json:"array,bytes=array"`
+		Sum    [4]byte  `json:"sum,bytes=hex"`
+		Key    [3]byte  `json:"key,bytes=base64"`
+		Plain  [2]byte  `json:"plain"`
+		Nested [][]byte `json:"nested,bytes=hex"`
+	}
+	v := Hashes{
+		Std:    []byte{0xfb, 0xff},
+		URL:    []byte{0xfb, 0xff},
+		Raw:    []byte{0xfb, 0xff},
+		Hex:    []byte{0xde, 0xad},
+		Array:  []byte{1, 2},
+		Sum:    [4]byte{0xca, 0xfe, 0xba, 0xbe},
+		Key:    [3]byte{'a', 'b', 'c'},
+		Plain:  [2]byte{3, 4},
+		Nested: [][]byte{{0x01}, {0x0a, 0xff}},
+	}
+	const expected = `{"array":[1,2],"hex":"dead","key":"YWJj","nested":["01","0aff"],"plain":[3,4],"raw":"+/8","std":"+/8=","sum":"cafebabe","url":"-_8="}`
+
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+
+	var decoded Hashes
+	require.NoError(t, Unmarshal(data, &decoded))
+	require.Equal(t, v, decoded)
+
+	require.NoError(t, Unmarshal([]byte(`{"sum":"0xCAFEBABF"}`), &decoded))
+	require.Equal(t, [4]byte{0xca, 0xfe, 0xba, 0xbf}, decoded.Sum)
+
+	// arrays are not truncated or zero-filled
+	var typeErr *UnmarshalTypeError
+	err = Unmarshal([]byte(`{"sum":"cafe"}`), &decoded)
+	require.Equal(t, true, errors.As(err, &typeErr))
+	require.Equal(t, "json: cannot unmarshal string of 2 bytes into Go struct field Hashes.sum of type [4]uint8", err.Error())
+	err = Unmarshal([]byte(`{"key":"YWJjZA=="}`), &decoded)
+	require.Equal(t, true, errors.As(err, &typeErr))
+	require.Equal(t, [4]byte{0xca, 0xfe, 0xba, 0xbf}, decoded.Sum)
+	require.Equal(t, [3]byte{'a', 'b', 'c'}, decoded.Key)
+
+	data, err = MarshalFlags(struct {
+		B []byte
+		A [2]byte
+	}{[]byte{0xab}, [2]byte{0xcd, 0xef}}, EncodeStandard|BytesHex)
+	require.NoError(t, err)
+	require.Equal(t, `{"A":"cdef","B":"ab"}`, string(data))
+}
+
+func TestMarshalPointerShapedItems(t *testing.T) {
+	type Items struct {
+		Array [2]map[string]int `json:"array"`
+		Slice []map[string]int  `json:"slice"`
+		Ptrs  []*int            `json:"ptrs"`
+	}
+	one := 1
+	cases := []struct {
+		value    any
+		expected string
+	}{
+		{[]map[string]int{{"a": 1}, nil}, `[{"a":1},null]`},
+		{[1]map[string]int{{"a": 1}}, `[{"a":1}]`},
+		{[2]map[string]int{{"a": 1}}, `[{"a":1},null]`},
+		{map[string]map[string]int{"x": {"a": 1}}, `{"x":{"a":1}}`},
+		{[1]*int{&one}, `[1]`},
+		{Items{[2]map[string]int{{"a": 1}}, []map[string]int{{"b": 2}}, []*int{&one, nil}}, `{"array":[{"a":1},null],"ptrs":[1,null],"slice":[{"b":2}]}`},
+	}
+	for _, c := range cases {
+		data, err := Marshal(c.value)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, string(data))
+	}
+}
@@ -0,0 +1,174 @@
+package jessy
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"unsafe"
+
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+var (
+	typeBigInt   = reflect.TypeFor[big.Int]()
+	typeBigFloat = reflect.TypeFor[big.Float]()
+	typeBigRat   = reflect.TypeFor[big.Rat]()
+)
+
+func bigIntEncoder(flags Flags) UnsafeEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+
+	if flags.Has(QuoteUnsafeInt64) && !flags.Has(NeedQuotes|QuoteInt64) {
+		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+			b := (*big.Int)(v)
+			if len(b.Bits()) == 0 {
+				if omitEmpty {
+					return dst, nil
+				}
+				return append(dst, '0'), nil
+			}
+			if b.BitLen() > 53 {
+				dst = append(dst, '"')
+				dst = b.Append(dst, 10)
+				dst = append(dst, '"')
+				return dst, nil
+			}
+			return b.Append(dst, 10), nil
+		}
+	}
+
+	if flags.Has(NeedQuotes | QuoteInt64) {
+		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+			b := (*big.Int)(v)
+			bits := b.Bits()
+			if len(bits) == 0 {
+				if omitEmpty {
+					return dst, nil
+				}
+				return append(dst, '"', '0', '"'), nil
+			}
+			dst = append(dst, '"')
+			if len(bits) == 1 {
+				if b.Sign() == -1 {
+					dst = append(dst, '-')
+				}
+				dst = zstr.AppendUint64(dst, uint64(bits[0]))
+			} else {
+				dst = b.Append(dst, 10)
+			}
+			dst = append(dst, '"')
+			return dst, nil
+		}
+	}
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		b := (*big.Int)(v)
+		bits := b.Bits()
+		if len(bits) == 0 {
+			if omitEmpty {
+				return dst, nil
+			}
+			return append(dst, '0'), nil
+		}
+		if len(bits) == 1 {
+			if b.Sign() == -1 {
+				dst = append(dst, '-')
+			}
+			return zstr.AppendUint64(dst, uint64(bits[0])), nil
+		}
+		return b.Append(dst, 10), nil
+	}
+}
+
+func bigFloatEncoder(flags Flags) UnsafeEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+	needQuotes := flags.Has(NeedQuotes)
+
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		f := (*big.Float)(v)
+		if f.Sign() == 0 {
+			if omitEmpty {
+				return dst, nil
+			}
+			if needQuotes {
+				return append(dst, '"', '0', '"'), nil
+			}
+			return append(dst, '0'), nil
+		}
+		if f.IsInf() {
+			return dst, errFloatNum
+		}
+		if needQuotes {
+			dst = append(dst, '"')
+			dst = f.Append(dst, 'g', -1)
+			dst = append(dst, '"')
+			return dst, nil
+		}
+		return f.Append(dst, 'g', -1), nil
+	}
+}
+
+// bigRatEncoder encodes rational as exact decimal number,
+// with ",string" option as quoted "a/b" fraction
+func bigRatEncoder(flags Flags) UnsafeEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+
+	if flags.Has(NeedQuotes) {
+		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+			r := (*big.Rat)(v)
+			if r.Sign() == 0 {
+				if omitEmpty {
+					return dst, nil
+				}
+				return append(dst, '"', '0', '"'), nil
+			}
+			dst = append(dst, '"')
+			dst = r.Num().Append(dst, 10)
+			if !r.IsInt() {
+				dst = append(dst, '/')
+				dst = r.Denom().Append(dst, 10)
+			}
+			dst = append(dst, '"')
+			return dst, nil
+		}
+	}
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		r := (*big.Rat)(v)
+		if r.Sign() == 0 {
+			if omitEmpty {
+				return dst, nil
+			}
+			return append(dst, '0'), nil
+		}
+		if r.IsInt() {
+			return r.Num().Append(dst, 10), nil
+		}
+		prec, ok := ratDecimalPrecision(r.Denom())
+		if !ok {
+			return dst, fmt.Errorf("big.Rat %s has no finite decimal representation, use ,string option", r.RatString())
+		}
+		return append(dst, r.FloatString(prec)...), nil
+	}
+}
+
+// ratDecimalPrecision returns the number of decimal digits
+// required to represent fraction with the denominator exactly
+func ratDecimalPrecision(denom *big.Int) (prec int, ok bool) {
+	twos := denom.TrailingZeroBits()
+	d := new(big.Int).Rsh(denom, twos)
+
+	var fives uint
+	five := big.NewInt(5)
+	q, m := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(d, five, m)
+		if m.Sign() != 0 {
+			break
+		}
+		d, q = q, d
+		fives++
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	return int(max(twos, fives)), true
+}
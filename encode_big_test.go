@@ -0,0 +1,58 @@
+package jessy
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestMarshalBigNumbers(t *testing.T) {
+	type Amounts struct {
+		Int      big.Int
+		Float    *big.Float
+		Rat      *big.Rat
+		RatStr   big.Rat    `json:",string"`
+		FloatStr *big.Float `json:",string"`
+		Empty    *big.Rat   `json:",omitempty"`
+	}
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	v := Amounts{
+		Float:    new(big.Float).SetPrec(200).SetFloat64(1.5),
+		Rat:      big.NewRat(-1234567, 1000),
+		FloatStr: big.NewFloat(0.25),
+	}
+	v.Int.Set(huge)
+	v.RatStr.SetFrac64(1, 3)
+
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, `{"Float":1.5,"FloatStr":"0.25","Int":123456789012345678901234567890,"Rat":-1234.567,"RatStr":"1/3"}`, string(data))
+
+	_, err = Marshal(big.NewRat(1, 3))
+	require.NotEqual(t, nil, err)
+
+	var decoded Amounts
+	require.NoError(t, Unmarshal(data, &decoded))
+	require.Equal(t, 0, decoded.Int.Cmp(huge))
+	require.Equal(t, 0, decoded.Float.Cmp(big.NewFloat(1.5)))
+	require.Equal(t, 0, decoded.Rat.Cmp(v.Rat))
+	require.Equal(t, 0, decoded.RatStr.Cmp(&v.RatStr))
+
+	const long = "12345678901234567890.123456789012345678901234567890"
+	decoded = Amounts{}
+	require.NoError(t, Unmarshal([]byte(`{"Float":`+long+`,"Rat":`+long+`}`), &decoded))
+	require.Equal(t, long, decoded.Rat.FloatString(30))
+	require.Equal(t, long, decoded.Float.Text('f', 30))
+
+	n := Number(long)
+	r, err := NumberBigRat(n)
+	require.NoError(t, err)
+	require.Equal(t, long, r.FloatString(30))
+	f, err := NumberBigFloat(n)
+	require.NoError(t, err)
+	require.Equal(t, long, f.Text('f', 30))
+	i, err := NumberBigInt("-123456789012345678901234567890")
+	require.NoError(t, err)
+	require.Equal(t, 0, i.Cmp(new(big.Int).Neg(huge)))
+}
@@ -0,0 +1,39 @@
+package jessy
+
+import (
+	"sync/atomic"
+
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+var colorPalette atomic.Pointer[zstr.Palette]
+
+func init() {
+	colorPalette.Store(&zstr.DefaultPalette)
+}
+
+// SetColorPalette sets ANSI colors of Colorize flag output
+func SetColorPalette(p zstr.Palette) {
+	colorPalette.Store(&p)
+}
+
+// MarshalColor is MarshalPretty with ANSI colors for terminals
+func MarshalColor(value any) ([]byte, error) {
+	return MarshalFlags(value, EncodeStandard|PrettySpaces|Colorize)
+}
+
+// AppendColor appends src JSON colored by palette of SetColorPalette
+func AppendColor(dst, src []byte) []byte {
+	return zstr.AppendColorJSON(dst, src, colorPalette.Load())
+}
+
+func encodeColorized(dst []byte, value any, flags Flags) ([]byte, error) {
+	buf := getMarshalBuf()
+	data, err := encodeAny(buf.AvailableBuffer(), value, flags)
+	if err == nil {
+		dst = zstr.AppendColorJSON(dst, data, colorPalette.Load())
+	}
+	buf.Grow(len(data))
+	putMarshalBuf(buf)
+	return dst, err
+}
@@ -0,0 +1,33 @@
+package jessy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+func TestMarshalColorize(t *testing.T) {
+	defer SetColorPalette(zstr.DefaultPalette)
+	SetColorPalette(zstr.Palette{Key: "K", String: "S", Number: "N", Bool: "B", Null: "Z"})
+
+	v := struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+		Ok    bool   `json:"ok"`
+		Ptr   *int   `json:"ptr"`
+		Tags  []any  `json:"tags"`
+	}{"a:b", -1, true, nil, []any{1.5e-7, false}}
+
+	data, err := MarshalFlags(v, EncodeStandard|Colorize)
+	require.NoError(t, err)
+	expected := `{K"count"R:N-1R,K"name"R:S"a:b"R,K"ok"R:BtrueR,K"ptr"R:ZnullR,K"tags"R:[N1.5e-7R,BfalseR]}`
+	require.Equal(t, expected, strings.ReplaceAll(string(data), zstr.ColorReset, "R"))
+
+	data, err = MarshalColor(v)
+	require.NoError(t, err)
+	pretty, err := MarshalPretty(v)
+	require.NoError(t, err)
+	require.Equal(t, string(AppendColor(nil, pretty)), string(data))
+}
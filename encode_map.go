@@ -9,6 +9,7 @@ import (
 	"unsafe"
 
 	"github.com/avpetkun/jessy-go/zgo"
+	"github.com/avpetkun/jessy-go/zstr"
 )
 
 func mapEncoder(deep, indent uint32, t reflect.Type, flags Flags, isDirectIface bool) UnsafeEncoder {
@@ -218,8 +219,9 @@ func mapEncoderUnsortedPretty(deep, indent uint32, t reflect.Type, flags Flags)
 	encodeVal := createItemTypeEncoder(deep, indent+1, flags, t.Elem())
 	getIterator := zgo.NewMapIteratorFromRType(t)
 
-	deepSpaces0 := getIndent(indent)
-	deepSpaces1 := getIndent(indent + 1)
+	deepSpaces0 := getIndent(flags, indent)
+	deepSpaces1 := getIndent(flags, indent+1)
+	width := getIndentStyle(flags).width
 
 	return func(dst []byte, value unsafe.Pointer) ([]byte, error) {
 		it, count := getIterator(value)
@@ -234,6 +236,7 @@ func mapEncoderUnsortedPretty(deep, indent uint32, t reflect.Type, flags Flags)
 			return append(dst, '{', '}'), nil
 		}
 
+		start := len(dst)
 		dst = append(dst, '{', '\n')
 		dstInitLen := len(dst)
 
@@ -273,6 +276,9 @@ func mapEncoderUnsortedPretty(deep, indent uint32, t reflect.Type, flags Flags)
 			dst = append(dst, '\n')
 			dst = append(dst, deepSpaces0...)
 			dst = append(dst, '}')
+			if width != 0 {
+				dst = zstr.CollapseIndent(dst, start, width, len(deepSpaces1), len(deepSpaces0))
+			}
 		} else {
 			dst[count-1] = '}'
 		}
@@ -287,8 +293,9 @@ func mapEncoderSortedPretty(deep, indent uint32, t reflect.Type, flags Flags) Un
 	encodeVal := createItemTypeEncoder(deep, indent+1, flags, t.Elem())
 	getIterator := zgo.NewMapIteratorFromRType(t)
 
-	deepSpaces0 := getIndent(indent)
-	deepSpaces1 := getIndent(indent + 1)
+	deepSpaces0 := getIndent(flags, indent)
+	deepSpaces1 := getIndent(flags, indent+1)
+	width := getIndentStyle(flags).width
 
 	return func(dst []byte, value unsafe.Pointer) ([]byte, error) {
 		it, count := getIterator(value)
@@ -303,6 +310,7 @@ func mapEncoderSortedPretty(deep, indent uint32, t reflect.Type, flags Flags) Un
 			return append(dst, '{', '}'), nil
 		}
 
+		start := len(dst)
 		dst = append(dst, '{', '\n')
 		dstInitLen := len(dst)
 
@@ -349,7 +357,7 @@ func mapEncoderSortedPretty(deep, indent uint32, t reflect.Type, flags Flags) Un
 		dstNewLen := len(dst)
 		mapSize := dstNewLen - dstInitLen
 		if mapSize == 0 {
-			dst = append(dst, '}')
+			dst[dstNewLen-1] = '}'
 		} else {
 			sort.Sort(buf)
 
@@ -367,6 +375,9 @@ func mapEncoderSortedPretty(deep, indent uint32, t reflect.Type, flags Flags) Un
 			dst = append(dst, '\n')
 			dst = append(dst, deepSpaces0...)
 			dst = append(dst, '}')
+			if width != 0 {
+				dst = zstr.CollapseIndent(dst, start, width, len(deepSpaces1), len(deepSpaces0))
+			}
 		}
 
 		mapSortBufPool.Put(buf)
@@ -10,14 +10,34 @@ import (
 	"github.com/avpetkun/jessy-go/zstr"
 )
 
-func marshalerEncoder(t reflect.Type, flags Flags) UnsafeEncoder {
+func marshalerEncoder(indent uint32, t reflect.Type, flags Flags) UnsafeEncoder {
 	getInterface := zgo.NewInterfacerFromRType[Marshaler](t)
 	if getInterface == nil {
 		return nullEncoder
 	}
+	if flags.Has(PrettySpaces) {
+		return marshalerEncoderPretty(indent, t, flags, getInterface)
+	}
 	omitEmpty := flags.Has(OmitEmpty)
 	escapeHTML := flags.Has(EscapeHTML)
+	escapeFlags := flags.escapeFlags()
 
+	if flags.Has(CompactMarshaler) && flags.Has(escapeStringFlags) {
+		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+			i := getInterface(v)
+			if i == nil {
+				if omitEmpty {
+					return dst, nil
+				}
+				return append(dst, 'n', 'u', 'l', 'l'), nil
+			}
+			data, err := i.MarshalJSON()
+			if err != nil {
+				return dst, errors.Join(fmt.Errorf("failed to call MarshalJSON of type <%s>", t), err)
+			}
+			return zstr.AppendCompactJSONFlags(dst, data, escapeFlags), nil
+		}
+	}
 	if flags.Has(CompactMarshaler) {
 		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
 			i := getInterface(v)
@@ -50,12 +70,68 @@ func marshalerEncoder(t reflect.Type, flags Flags) UnsafeEncoder {
 	}
 }
 
-func appendMarshalerEncoder(t reflect.Type, flags Flags) UnsafeEncoder {
+// marshalerEncoderPretty reindents MarshalJSON output to the indent level of pretty output
+func marshalerEncoderPretty(indent uint32, t reflect.Type, flags Flags, getInterface func(unsafe.Pointer) Marshaler) UnsafeEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+	needCompact := flags.Has(CompactMarshaler) && flags.Has(EscapeHTML|escapeStringFlags)
+	escapeFlags := flags.escapeFlags()
+	linePrefix := string(getIndent(flags, indent))
+	style := getIndentStyle(flags)
+
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		i := getInterface(v)
+		if i == nil {
+			if omitEmpty {
+				return dst, nil
+			}
+			return append(dst, 'n', 'u', 'l', 'l'), nil
+		}
+		data, err := i.MarshalJSON()
+		if err != nil {
+			return dst, errors.Join(fmt.Errorf("failed to call MarshalJSON of type <%s>", t), err)
+		}
+		if !needCompact {
+			return zstr.AppendIndentInlineWidth(dst, data, linePrefix, style.indent, style.width), nil
+		}
+		buf := getMarshalBuf()
+		data = zstr.AppendCompactJSONFlags(buf.AvailableBuffer(), data, escapeFlags)
+		dst = zstr.AppendIndentInlineWidth(dst, data, linePrefix, style.indent, style.width)
+		buf.Grow(len(data))
+		putMarshalBuf(buf)
+		return dst, nil
+	}
+}
+
+func appendMarshalerEncoder(indent uint32, t reflect.Type, flags Flags) UnsafeEncoder {
 	omitEmpty := flags.Has(OmitEmpty)
 	getInterface := zgo.NewInterfacerFromRType[AppendMarshaler](t)
 	if getInterface == nil {
 		return nullEncoder
 	}
+	if flags.Has(PrettySpaces) {
+		linePrefix := string(getIndent(flags, indent))
+		style := getIndentStyle(flags)
+		return func(dst []byte, v unsafe.Pointer) (newDst []byte, err error) {
+			i := getInterface(v)
+			if i == nil {
+				if omitEmpty {
+					return dst, nil
+				}
+				return append(dst, 'n', 'u', 'l', 'l'), nil
+			}
+			start := len(dst)
+			newDst, err = i.AppendJSON(dst)
+			if err != nil {
+				return dst, errors.Join(fmt.Errorf("failed to call AppendJSON of type <%s>", t), err)
+			}
+			buf := getMarshalBuf()
+			data := append(buf.AvailableBuffer(), newDst[start:]...)
+			newDst = zstr.AppendIndentInlineWidth(newDst[:start], data, linePrefix, style.indent, style.width)
+			buf.Grow(len(data))
+			putMarshalBuf(buf)
+			return newDst, nil
+		}
+	}
 	return func(dst []byte, v unsafe.Pointer) (newDst []byte, err error) {
 		i := getInterface(v)
 		if i == nil {
@@ -82,6 +158,24 @@ func textMarshalerEncoder(t reflect.Type, flags Flags) UnsafeEncoder {
 		return nullEncoder
 	}
 
+	if flags.Has(EscapeUnicode|EscapeSlash) || (needValidate && flags.Has(KeepInvalidUTF8)) {
+		escapeFlags := flags.escapeFlags()
+		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+			i := getInterface(v)
+			if i == nil {
+				if omitEmpty {
+					return dst, nil
+				}
+				return append(dst, 'n', 'u', 'l', 'l'), nil
+			}
+			data, err := i.MarshalText()
+			if err != nil {
+				return dst, errors.Join(fmt.Errorf("failed to call MarshalText of type <%s>", t), err)
+			}
+			return zstr.AppendQuotedStringFlags(dst, data, escapeFlags), nil
+		}
+	}
+
 	if needValidate {
 		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
 			i := getInterface(v)
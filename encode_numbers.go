@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"unsafe"
 
+	"github.com/avpetkun/jessy-go/zgo"
 	"github.com/avpetkun/jessy-go/zstr"
 )
 
@@ -24,6 +25,10 @@ func intEncoder(flags Flags) UnsafeEncoder {
 }
 
 func uint64Encoder(flags Flags) UnsafeEncoder {
+	if flags.Has(QuoteInt64 | QuoteUnsafeInt64) {
+		return uint64QuotedEncoder(flags)
+	}
+
 	omitEmpty := flags.Has(OmitEmpty)
 	needQuotes := flags.Has(NeedQuotes)
 
@@ -71,6 +76,10 @@ func uint64Encoder(flags Flags) UnsafeEncoder {
 }
 
 func int64Encoder(flags Flags) UnsafeEncoder {
+	if flags.Has(QuoteInt64 | QuoteUnsafeInt64) {
+		return int64QuotedEncoder(flags)
+	}
+
 	omitEmpty := flags.Has(OmitEmpty)
 	needQuotes := flags.Has(NeedQuotes)
 
@@ -117,6 +126,57 @@ func int64Encoder(flags Flags) UnsafeEncoder {
 	}
 }
 
+// maxSafeInteger is the JavaScript Number.MAX_SAFE_INTEGER
+const maxSafeInteger = 1<<53 - 1
+
+func uint64QuotedEncoder(flags Flags) UnsafeEncoder {
+	if flags.Has(QuoteInt64 | NeedQuotes) {
+		return uint64Encoder(flags.Exclude(QuoteInt64|QuoteUnsafeInt64) | NeedQuotes)
+	}
+	omitEmpty := flags.Has(OmitEmpty)
+
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		n := *(*uint64)(v)
+		if n == 0 {
+			if omitEmpty {
+				return dst, nil
+			}
+			return append(dst, '0'), nil
+		}
+		if n > maxSafeInteger {
+			dst = append(dst, '"')
+			dst = zstr.AppendUint64(dst, n)
+			dst = append(dst, '"')
+			return dst, nil
+		}
+		return zstr.AppendUint64(dst, n), nil
+	}
+}
+
+func int64QuotedEncoder(flags Flags) UnsafeEncoder {
+	if flags.Has(QuoteInt64 | NeedQuotes) {
+		return int64Encoder(flags.Exclude(QuoteInt64|QuoteUnsafeInt64) | NeedQuotes)
+	}
+	omitEmpty := flags.Has(OmitEmpty)
+
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		n := *(*int64)(v)
+		if n == 0 {
+			if omitEmpty {
+				return dst, nil
+			}
+			return append(dst, '0'), nil
+		}
+		if n > maxSafeInteger || n < -maxSafeInteger {
+			dst = append(dst, '"')
+			dst = zstr.AppendInt64(dst, n)
+			dst = append(dst, '"')
+			return dst, nil
+		}
+		return zstr.AppendInt64(dst, n), nil
+	}
+}
+
 func uint32Encoder(flags Flags) UnsafeEncoder {
 	omitEmpty := flags.Has(OmitEmpty)
 	needQuotes := flags.Has(NeedQuotes)
@@ -400,6 +460,10 @@ func int8Encoder(flags Flags) UnsafeEncoder {
 }
 
 func float32Encoder(flags Flags) UnsafeEncoder {
+	if flags.Has(floatFormatFlags) {
+		return floatFormatEncoder(flags, 32)
+	}
+
 	omitEmpty := flags.Has(OmitEmpty)
 	needQuotes := flags.Has(NeedQuotes)
 
@@ -459,6 +523,10 @@ func float32Encoder(flags Flags) UnsafeEncoder {
 }
 
 func float64Encoder(flags Flags) UnsafeEncoder {
+	if flags.Has(floatFormatFlags) {
+		return floatFormatEncoder(flags, 64)
+	}
+
 	omitEmpty := flags.Has(OmitEmpty)
 	needQuotes := flags.Has(NeedQuotes)
 
@@ -517,6 +585,36 @@ func float64Encoder(flags Flags) UnsafeEncoder {
 	}
 }
 
+func floatFormatEncoder(flags Flags, bitSize int) UnsafeEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+	needQuotes := flags.Has(NeedQuotes)
+
+	load := func(v unsafe.Pointer) float64 { return *(*float64)(v) }
+	if bitSize == 32 {
+		load = func(v unsafe.Pointer) float64 { return float64(*(*float32)(v)) }
+	}
+
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		n := load(v)
+		if n == 0 {
+			if omitEmpty {
+				return dst, nil
+			}
+			n = 0 // drop negative zero sign
+		}
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return dst, errFloatNum
+		}
+		if needQuotes {
+			dst = append(dst, '"')
+			dst = appendFloatFormat(dst, n, bitSize, flags)
+			dst = append(dst, '"')
+			return dst, nil
+		}
+		return appendFloatFormat(dst, n, bitSize, flags), nil
+	}
+}
+
 func complex64Encoder(flags Flags) UnsafeEncoder {
 	if flags.Has(OmitEmpty) {
 		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
@@ -642,3 +740,23 @@ func appendFloat64(b []byte, f float64) []byte {
 	}
 	return b
 }
+
+// appendFloatFormat formats float by FloatPrecision, FloatDigits and FloatNoExponent flags
+func appendFloatFormat(b []byte, f float64, bitSize int, flags Flags) []byte {
+	if flags.Has(floatFormatFixed) {
+		return strconv.AppendFloat(b, f, 'f', flags.floatPrecision(), bitSize)
+	}
+	if flags.Has(floatFormatDigits) {
+		// round to significant digits and then format the shortest representation
+		var buf [32]byte
+		rounded := strconv.AppendFloat(buf[:0], f, 'e', flags.floatPrecision()-1, bitSize)
+		f, _ = strconv.ParseFloat(zgo.B2S(rounded), bitSize)
+	}
+	if flags.Has(FloatNoExponent) {
+		return strconv.AppendFloat(b, f, 'f', -1, bitSize)
+	}
+	if bitSize == 32 {
+		return appendFloat32(b, f)
+	}
+	return appendFloat64(b, f)
+}
@@ -0,0 +1,99 @@
+package jessy
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestMarshalFloatFormat(t *testing.T) {
+	{
+		data, err := Marshal(struct {
+			Price    float64   `json:"price,precision=2"`
+			Zero     float64   `json:"zero,precision=2"`
+			Rate     float32   `json:"rate,digits=3"`
+			Small    float64   `json:"small,noexp"`
+			Big      float64   `json:"big,digits=2,noexp"`
+			Quoted   float64   `json:"quoted,string,precision=1"`
+			Omit     float64   `json:"omit,omitempty,precision=3"`
+			Points   []float64 `json:"points,precision=1"`
+			Standard float64   `json:"standard"`
+		}{
+			Price:    12.5,
+			Rate:     3.14159,
+			Small:    0.0000001,
+			Big:      123456789e20,
+			Quoted:   -0.25,
+			Points:   []float64{1, 2.25, -3.75},
+			Standard: 0.0000001,
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"big":12000000000000000000000000000,"points":[1.0,2.2,-3.8],"price":12.50,"quoted":"-0.2","rate":3.14,"small":0.0000001,"standard":1e-7,"zero":0.00}`, string(data))
+	}
+	{
+		data, err := MarshalFlags([]float64{1, 0.5, 1e-9}, EncodeStandard|FloatPrecision(3))
+		require.NoError(t, err)
+		require.Equal(t, `[1.000,0.500,0.000]`, string(data))
+	}
+	{
+		data, err := MarshalFlags([]float64{123.456, 1e25}, EncodeStandard|FloatDigits(2))
+		require.NoError(t, err)
+		require.Equal(t, `[120,1e+25]`, string(data))
+	}
+	{
+		// field tag overrides global precision
+		data, err := MarshalFlags(struct {
+			A float64
+			B float64 `json:",digits=1"`
+		}{1.25, 1.25}, EncodeStandard|FloatPrecision(4))
+		require.NoError(t, err)
+		require.Equal(t, `{"A":1.2500,"B":1}`, string(data))
+	}
+}
+
+func TestMarshalQuotedInt64(t *testing.T) {
+	type Ids struct {
+		Small  int64    `json:"small,int64=safe"`
+		Big    int64    `json:"big,int64=safe"`
+		Neg    int64    `json:"neg,int64=safe"`
+		Uint   uint64   `json:"uint,int64=safe"`
+		Always int      `json:"always,int64=string"`
+		Slice  []uint64 `json:"slice,int64=safe"`
+		BigInt *big.Int `json:"bigint,int64=safe"`
+		Plain  int64    `json:"plain"`
+	}
+	v := Ids{
+		Small:  1<<53 - 1,
+		Big:    1<<53 + 1,
+		Neg:    -(1 << 60),
+		Uint:   math.MaxUint64,
+		Always: 7,
+		Slice:  []uint64{1, 1 << 62},
+		BigInt: new(big.Int).Lsh(big.NewInt(1), 70),
+		Plain:  1 << 60,
+	}
+	const expected = `{"always":"7","big":"9007199254740993","bigint":"1180591620717411303424","neg":"-1152921504606846976","plain":1152921504606846976,"slice":[1,"4611686018427387904"],"small":9007199254740991,"uint":"18446744073709551615"}`
+
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+
+	var decoded Ids
+	require.NoError(t, Unmarshal(data, &decoded))
+	require.Equal(t, v, decoded)
+
+	// unquoted form is accepted as well
+	require.NoError(t, Unmarshal([]byte(`{"always":7,"big":9007199254740993,"bigint":"5"}`), &decoded))
+	require.Equal(t, 7, decoded.Always)
+	require.Equal(t, int64(1<<53+1), decoded.Big)
+	require.Equal(t, big.NewInt(5), decoded.BigInt)
+
+	data, err = MarshalFlags([]any{int64(1), uint(1 << 60)}, EncodeStandard|QuoteInt64)
+	require.NoError(t, err)
+	require.Equal(t, `["1","1152921504606846976"]`, string(data))
+
+	// plain fields still reject quoted integers
+	require.NotEqual(t, nil, Unmarshal([]byte(`{"plain":"1"}`), &decoded))
+}
@@ -0,0 +1,269 @@
+package jessy
+
+import (
+	"errors"
+	"reflect"
+	"unsafe"
+
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+// seqEncoding iterates values of iter.Seq and receiving channels encoded as arrays
+// and pairs of iter.Seq2 encoded as objects
+type seqEncoding struct {
+	key  reflect.Type // nil for arrays
+	elem reflect.Type
+	// each calls yield for items of non-nil v until yield returns false,
+	// key and elem point to copies of them valid until yield returns
+	each func(v reflect.Value, yield func(key, elem unsafe.Pointer) bool)
+}
+
+var seqYieldResults = [2][]reflect.Value{{reflect.ValueOf(false)}, {reflect.ValueOf(true)}}
+
+// errSeqContinued is panicked like range-over-func panics when yield is called after it returned false
+var errSeqContinued = errors.New("json: iter.Seq continued iteration after yield returned false")
+
+// newSeqEncoding returns iteration of functions of iter.Seq and iter.Seq2 shape,
+// channels are iterated only with EncodeChannels flag. Nil is returned for other types
+func newSeqEncoding(t reflect.Type, flags Flags) *seqEncoding {
+	switch t.Kind() {
+	case reflect.Func:
+		if t.NumIn() != 1 || t.NumOut() != 0 || t.IsVariadic() {
+			return nil
+		}
+		yieldType := t.In(0)
+		if yieldType.Kind() != reflect.Func || yieldType.NumOut() != 1 || yieldType.Out(0).Kind() != reflect.Bool || yieldType.IsVariadic() {
+			return nil
+		}
+		seq := &seqEncoding{elem: yieldType.In(yieldType.NumIn() - 1)}
+		switch yieldType.NumIn() {
+		case 1:
+		case 2:
+			if seq.key = yieldType.In(0); !tSeqKey(seq.key) {
+				return nil
+			}
+		default:
+			return nil
+		}
+		results := seqYieldResults
+		if yieldType.Out(0) != results[0][0].Type() {
+			out := yieldType.Out(0)
+			results = [2][]reflect.Value{{results[0][0].Convert(out)}, {results[1][0].Convert(out)}}
+		}
+		seq.each = func(fn reflect.Value, yield func(key, elem unsafe.Pointer) bool) {
+			var keySlot reflect.Value
+			if seq.key != nil {
+				keySlot = reflect.New(seq.key)
+			}
+			elemSlot := reflect.New(seq.elem)
+			done := false
+			fn.Call([]reflect.Value{reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+				if done {
+					panic(errSeqContinued)
+				}
+				var key unsafe.Pointer
+				if seq.key != nil {
+					keySlot.Elem().Set(args[0])
+					key = keySlot.UnsafePointer()
+				}
+				elemSlot.Elem().Set(args[len(args)-1])
+				if yield(key, elemSlot.UnsafePointer()) {
+					return results[1]
+				}
+				done = true
+				return results[0]
+			})})
+			done = true
+		}
+		return seq
+
+	case reflect.Chan:
+		if !flags.Has(EncodeChannels) || t.ChanDir()&reflect.RecvDir == 0 {
+			return nil
+		}
+		seq := &seqEncoding{elem: t.Elem()}
+		seq.each = func(ch reflect.Value, yield func(key, elem unsafe.Pointer) bool) {
+			elemSlot := reflect.New(seq.elem)
+			for {
+				v, ok := ch.Recv()
+				if !ok {
+					return
+				}
+				elemSlot.Elem().Set(v)
+				if !yield(nil, elemSlot.UnsafePointer()) {
+					return
+				}
+			}
+		}
+		return seq
+	}
+	return nil
+}
+
+// tSeqKey reports types of iter.Seq2 keys encoded as object keys like keys of maps
+func tSeqKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(typeTextMarshaler) || reflect.PointerTo(t).Implements(typeTextMarshaler)
+}
+
+// seqValue returns function or channel at v, v is the value itself if it is not ifaceIndir
+func seqValue(t reflect.Type, v unsafe.Pointer, ifaceIndir bool) reflect.Value {
+	if !ifaceIndir {
+		return reflect.NewAt(t, unsafe.Pointer(&v)).Elem()
+	}
+	return reflect.NewAt(t, v).Elem()
+}
+
+func seqEncoder(deep, indent uint32, t reflect.Type, flags Flags, ifaceIndir bool, seq *seqEncoding) UnsafeEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+	pretty := flags.Has(PrettySpaces)
+
+	var encodeKey UnsafeEncoder
+	open, close := byte('['), byte(']')
+	if seq.key != nil {
+		encodeKey = createItemTypeEncoder(deep, indent+1, (flags | NeedQuotes), seq.key)
+		open, close = '{', '}'
+	}
+	encodeElem := createItemTypeEncoder(deep, indent+1, flags, seq.elem)
+
+	deepSpaces0 := getIndent(flags, indent)
+	deepSpaces1 := getIndent(flags, indent+1)
+	width := getIndentStyle(flags).width
+
+	return func(dst []byte, value unsafe.Pointer) ([]byte, error) {
+		fn := seqValue(t, value, ifaceIndir)
+		if fn.IsNil() {
+			if omitEmpty {
+				return dst, nil
+			}
+			return append(dst, 'n', 'u', 'l', 'l'), nil
+		}
+
+		start := len(dst)
+		dst = append(dst, open)
+		if pretty {
+			dst = append(dst, '\n')
+		}
+		dstInitLen := len(dst)
+
+		var err error
+		seq.each(fn, func(key, elem unsafe.Pointer) bool {
+			itemIndex := len(dst)
+			if pretty {
+				dst = append(dst, deepSpaces1...)
+			}
+			if encodeKey != nil {
+				keyStart := len(dst)
+				if dst, err = encodeKey(dst, key); err != nil {
+					return false
+				}
+				if len(dst) == keyStart {
+					dst = dst[:itemIndex]
+					return true
+				}
+				if pretty {
+					dst = append(dst, ':', ' ')
+				} else {
+					dst = append(dst, ':')
+				}
+			}
+			valIndex := len(dst)
+			if dst, err = encodeElem(dst, elem); err != nil {
+				return false
+			}
+			if len(dst) == valIndex {
+				dst = dst[:itemIndex]
+				return true
+			}
+			if pretty {
+				dst = append(dst, ',', '\n')
+			} else {
+				dst = append(dst, ',')
+			}
+			return true
+		})
+		if err != nil {
+			return dst, err
+		}
+
+		switch {
+		case len(dst) == dstInitLen:
+			dst = append(dst[:start], open, close)
+		case pretty:
+			dst = dst[:len(dst)-2]
+			dst = append(dst, '\n')
+			dst = append(dst, deepSpaces0...)
+			dst = append(dst, close)
+			if width != 0 {
+				dst = zstr.CollapseIndent(dst, start, width, len(deepSpaces1), len(deepSpaces0))
+			}
+		default:
+			dst[len(dst)-1] = close
+		}
+		return dst, nil
+	}
+}
+
+func seqStreamEncoder(deep, indent uint32, t reflect.Type, flags Flags, ifaceIndir bool, seq *seqEncoding) streamEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+
+	var encodeKey UnsafeEncoder
+	open, close := byte('['), byte(']')
+	if seq.key != nil {
+		encodeKey = createItemTypeEncoder(deep, indent+1, (flags | NeedQuotes), seq.key)
+		open, close = '{', '}'
+	}
+	elemEncoder := createStreamItemEncoder(deep, indent+1, flags.Exclude(OmitEmpty), seq.elem, true)
+	seps := newStreamSeps(indent, flags)
+
+	return func(s *encodeStream, value unsafe.Pointer) error {
+		fn := seqValue(t, value, ifaceIndir)
+		if fn.IsNil() {
+			if !omitEmpty {
+				s.buf = append(s.buf, 'n', 'u', 'l', 'l')
+			}
+			return nil
+		}
+
+		start, flushes := len(s.buf), s.flushes
+		s.buf = append(s.buf, open)
+		items := 0
+		var err error
+		seq.each(fn, func(key, elem unsafe.Pointer) bool {
+			itemIndex, itemFlushes := len(s.buf), s.flushes
+			s.buf = seps.appendItem(s.buf, items)
+			if encodeKey != nil {
+				keyStart := len(s.buf)
+				if s.buf, err = encodeKey(s.buf, key); err != nil {
+					return false
+				}
+				if len(s.buf) == keyStart {
+					s.buf = s.buf[:itemIndex]
+					return true
+				}
+				s.buf = append(s.buf, seps.colon...)
+			}
+			valIndex := len(s.buf)
+			if err = elemEncoder(s, elem); err != nil {
+				return false
+			}
+			if s.flushes == itemFlushes && len(s.buf) == valIndex {
+				s.buf = s.buf[:itemIndex]
+				return true
+			}
+			items++
+			err = seps.flush(s, start, flushes, items)
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+		seps.appendClose(s, close, items, start, flushes)
+		return nil
+	}
+}
@@ -0,0 +1,169 @@
+//go:build go1.23
+
+package jessy
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+type seqRow struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func seqRows(n int) iter.Seq[seqRow] {
+	return func(yield func(seqRow) bool) {
+		for i := range n {
+			if !yield(seqRow{ID: i, Name: fmt.Sprint("row-", i)}) {
+				return
+			}
+		}
+	}
+}
+
+func TestMarshalSeq(t *testing.T) {
+	type Response struct {
+		Rows   iter.Seq[seqRow]           `json:"rows"`
+		Counts iter.Seq2[string, int]     `json:"counts"`
+		Codes  func(func(int, bool) bool) `json:"codes"`
+		Nil    iter.Seq[int]              `json:"nil"`
+		Omit   iter.Seq[int]              `json:"omit,omitempty"`
+	}
+	v := Response{
+		Rows:   seqRows(2),
+		Counts: maps.All(map[string]int{"a": 1}),
+		Codes: func(yield func(int, bool) bool) {
+			_ = yield(2, true) && yield(1, false)
+		},
+	}
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, `{"codes":{"2":true,"1":false},"counts":{"a":1},"nil":null,"rows":[{"id":0,"name":"row-0"},{"id":1,"name":"row-1"}]}`, string(data))
+
+	data, err = MarshalIndent(seqRows(1), "", "  ")
+	require.NoError(t, err)
+	require.Equal(t, "[\n  {\n    \"id\": 0,\n    \"name\": \"row-0\"\n  }\n]", string(data))
+
+	data, err = Marshal(slices.Values([]int{}))
+	require.NoError(t, err)
+	require.Equal(t, `[]`, string(data))
+	data, err = MarshalPretty(maps.All(map[int]int{}))
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(data))
+
+	// iteration stops at the first error
+	yielded := 0
+	_, err = Marshal(func(yield func(any) bool) {
+		for _, v := range []any{1, errorMarshaler{}, 3} {
+			yielded++
+			if !yield(v) {
+				return
+			}
+		}
+	})
+	require.NotEqual(t, nil, err)
+	require.Equal(t, 2, yielded)
+
+	// yield must not be called after it returned false
+	func() {
+		defer func() { require.Equal(t, errSeqContinued, recover()) }()
+		_, _ = Marshal(func(yield func(any) bool) {
+			_ = yield(errorMarshaler{})
+			_ = yield(1)
+		})
+	}()
+}
+
+type errorMarshaler struct{}
+
+func (errorMarshaler) MarshalJSON() ([]byte, error) { return nil, errors.New("fail") }
+
+func TestMarshalChannels(t *testing.T) {
+	ch := make(chan string, 3)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+	data, err := MarshalFlags(struct {
+		Ch   <-chan string `json:"ch"`
+		Send chan<- int    `json:"send"`
+		Nil  chan int      `json:"nil"`
+	}{Ch: ch}, EncodeStandard|EncodeChannels|SkipUnsupported)
+	require.NoError(t, err)
+	require.Equal(t, `{"ch":["a","b"],"nil":null}`, string(data))
+
+	// channels are not received without the flag
+	ch2 := make(chan int, 1)
+	ch2 <- 1
+	close(ch2)
+	type withChan struct {
+		Ch chan int `json:"ch"`
+	}
+	_, err = Marshal(withChan{ch2})
+	var typeErr *UnsupportedTypeError
+	require.Equal(t, true, errors.As(err, &typeErr))
+	data, err = MarshalFlags(withChan{ch2}, EncodeStandard|SkipUnsupported)
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(data))
+	require.Equal(t, 1, len(ch2))
+}
+
+func TestEncoderStreamSeq(t *testing.T) {
+	rows := func(yield func(int, seqRow) bool) {
+		seqRows(500)(func(row seqRow) bool {
+			return yield(row.ID, row)
+		})
+	}
+	var expected strings.Builder
+	require.NoError(t, NewEncoder(&expected).Encode(iter.Seq2[int, seqRow](rows)))
+
+	var w chunksWriter
+	enc := NewEncoder(&w)
+	enc.SetFlushThreshold(1024)
+	require.NoError(t, enc.Encode(iter.Seq2[int, seqRow](rows)))
+	require.Equal(t, expected.String(), w.String())
+	if len(w.chunks) < 10 {
+		t.Fatalf("%d chunks of %d bytes", len(w.chunks), w.Len())
+	}
+
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	w = chunksWriter{}
+	enc = NewEncoderWithFlags(&w, EncodeStandard|EncodeChannels)
+	enc.SetFlushThreshold(1)
+	require.NoError(t, enc.Encode(ch))
+	require.Equal(t, "[1,2]\n", w.String())
+}
+
+func BenchmarkMarshalSeq(b *testing.B) {
+	items := make([]seqRow, 100)
+	for i := range items {
+		items[i] = seqRow{ID: i, Name: fmt.Sprint("row-", i)}
+	}
+	for _, c := range []struct {
+		name  string
+		value any
+	}{
+		{"slice", items},
+		{"seq", slices.Values(items)},
+		{"seq2", slices.All(items)},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			buf := make([]byte, 0, 4096)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				buf, _ = Append(buf[:0], c.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,460 @@
+package jessy
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/avpetkun/jessy-go/zgo"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+// streamEncoder encodes value into stream buffer and flushes it
+// to the writer between items of structs, slices, arrays and maps
+type streamEncoder func(s *encodeStream, v unsafe.Pointer) error
+
+type encodeStream struct {
+	w         io.Writer
+	buf       []byte
+	threshold int
+	flushes   int
+}
+
+// flush writes buffer when it has grown over threshold
+func (s *encodeStream) flush() error {
+	if len(s.buf) < s.threshold {
+		return nil
+	}
+	_, err := s.w.Write(s.buf)
+	s.buf = s.buf[:0]
+	s.flushes++
+	return err
+}
+
+var streamEncodersCache sync.Map
+
+func getStreamEncoder(typ *zgo.Type, flags Flags, indent uint32) streamEncoder {
+	key := encoderCacheKey{typ, flags, indent}
+	if val, ok := streamEncodersCache.Load(key); ok {
+		return val.(streamEncoder)
+	}
+	encoder := createStreamItemEncoder(0, indent, flags, typ.Native(), typ.IfaceIndir())
+	streamEncodersCache.Store(key, encoder)
+	return encoder
+}
+
+func leafStreamEncoder(encode UnsafeEncoder) streamEncoder {
+	return func(s *encodeStream, v unsafe.Pointer) (err error) {
+		s.buf, err = encode(s.buf, v)
+		return
+	}
+}
+
+// createStreamItemEncoder returns stream encoder of containers
+// or wraps usual encoder of anything else
+func createStreamItemEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir bool) streamEncoder {
+	if encoder, ok := createStreamEncoder(deep, indent, flags, t, ifaceIndir); ok {
+		return encoder
+	}
+	return leafStreamEncoder(createTypeEncoder(deep, indent, flags, t, ifaceIndir, false))
+}
+
+func createStreamEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir bool) (streamEncoder, bool) {
+	if t.Kind() == reflect.Pointer {
+		return pointerStreamEncoder(deep, indent, flags, t, ifaceIndir)
+	}
+	if tStreamLeaf(t) {
+		return nil, false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return structStreamEncoder(deep, indent, flags, t, ifaceIndir)
+	case reflect.Map:
+		return mapStreamEncoder(deep, indent, t, flags, ifaceIndir), true
+	case reflect.Slice:
+		return sliceStreamEncoder(deep, indent, t, flags)
+	case reflect.Array:
+		return arrayStreamEncoder(deep, indent, t, flags, ifaceIndir)
+	case reflect.Interface:
+		return interfaceStreamEncoder(indent, flags), true
+	case reflect.Func, reflect.Chan:
+		if seq := newSeqEncoding(t, flags); seq != nil {
+			return seqStreamEncoder(deep, indent, t, flags, ifaceIndir, seq), true
+		}
+	}
+	return nil, false
+}
+
+// tStreamLeaf reports types with own encoders which are written at once
+func tStreamLeaf(t reflect.Type) bool {
+	for i := range customEncoders {
+		if customEncoders[i].Type == t {
+			return true
+		}
+	}
+	switch t {
+	case timeType, typeBigInt, typeBigFloat, typeBigRat:
+		return true
+	}
+	tp := reflect.PointerTo(t)
+	for _, it := range [...]reflect.Type{typeAppendMarshaler, typeMarshaler, typeAppendTextMarshaler, typeTextMarshaler} {
+		if tReallyImplements(t, it) || tReallyImplements(tp, it) {
+			return true
+		}
+	}
+	return false
+}
+
+func pointerStreamEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir bool) (streamEncoder, bool) {
+	elemEncoder, ok := createStreamEncoder(deep, indent, flags.Exclude(OmitEmpty), t.Elem(), true)
+	if !ok {
+		return nil, false
+	}
+	omitEmpty := flags.Has(OmitEmpty)
+	needQuotes := flags.Has(NeedQuotes)
+	return func(s *encodeStream, v unsafe.Pointer) error {
+		if ifaceIndir {
+			v = *(*unsafe.Pointer)(v)
+		}
+		if v == nil {
+			if needQuotes {
+				s.buf = append(s.buf, '"', '"')
+			} else if !omitEmpty {
+				s.buf = append(s.buf, 'n', 'u', 'l', 'l')
+			}
+			return nil
+		}
+		return elemEncoder(s, v)
+	}, true
+}
+
+func interfaceStreamEncoder(indent uint32, flags Flags) streamEncoder {
+	if !flags.Has(PrettySpaces) {
+		indent = 0
+	}
+	return func(s *encodeStream, v unsafe.Pointer) error {
+		eface := (*zgo.EmptyInterface)(v)
+		if eface.Type == nil {
+			s.buf = append(s.buf, 'n', 'u', 'l', 'l')
+			return nil
+		}
+		return getStreamEncoder(eface.Type, flags, indent)(s, eface.Data)
+	}
+}
+
+// streamSeps holds separators of container items
+type streamSeps struct {
+	first []byte
+	next  []byte
+	end   []byte
+	colon []byte
+
+	width int
+	inner int
+	outer int
+}
+
+func newStreamSeps(indent uint32, flags Flags) *streamSeps {
+	if !flags.Has(PrettySpaces) {
+		return &streamSeps{next: []byte{','}, colon: []byte{':'}}
+	}
+	deepSpaces0 := getIndent(flags, indent)
+	deepSpaces1 := getIndent(flags, indent+1)
+	return &streamSeps{
+		first: append([]byte{'\n'}, deepSpaces1...),
+		next:  append([]byte{',', '\n'}, deepSpaces1...),
+		end:   append([]byte{'\n'}, deepSpaces0...),
+		colon: []byte{':', ' '},
+		width: getIndentStyle(flags).width,
+		inner: len(deepSpaces1),
+		outer: len(deepSpaces0),
+	}
+}
+
+func (p *streamSeps) appendItem(dst []byte, items int) []byte {
+	if items != 0 {
+		return append(dst, p.next...)
+	}
+	return append(dst, p.first...)
+}
+
+// flush writes buffer of container started at start with items written,
+// but keeps it while the container still may be collapsed by width
+func (p *streamSeps) flush(s *encodeStream, start, flushes, items int) error {
+	if p.width != 0 && s.flushes == flushes {
+		// one line size without item indents plus spaces after commas
+		if len(s.buf)-start-items*(1+p.inner)+items-1 <= p.width {
+			return nil
+		}
+	}
+	return s.flush()
+}
+
+// appendClose closes container started at start,
+// it is collapsed by width only if nothing was flushed since start
+func (p *streamSeps) appendClose(s *encodeStream, c byte, items, start, flushes int) {
+	if items == 0 {
+		s.buf = append(s.buf, c)
+		return
+	}
+	s.buf = append(s.buf, p.end...)
+	s.buf = append(s.buf, c)
+	if p.width != 0 && s.flushes == flushes {
+		s.buf = zstr.CollapseIndent(s.buf, start, p.width, p.inner, p.outer)
+	}
+}
+
+func structStreamEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir bool) (streamEncoder, bool) {
+	if deep++; deep >= marshalMaxDeep {
+		return nil, false
+	}
+	fields := getStructFields(deep, indent, flags, t, ifaceIndir, false)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	encoders := make([]streamEncoder, len(fields))
+	for i := range fields {
+		// embedded structs are written at once
+		if fields[i].KeyLen != 0 {
+			encoders[i], _ = createStreamEncoder(deep, indent+1, fields[i].flags, fields[i].typ, ifaceIndir)
+		}
+		if encoders[i] == nil {
+			encoders[i] = leafStreamEncoder(fields[i].Encoder)
+		}
+	}
+	seps := newStreamSeps(indent, flags)
+
+	return func(s *encodeStream, v unsafe.Pointer) error {
+		start, flushes := len(s.buf), s.flushes
+		s.buf = append(s.buf, '{')
+		items := 0
+		for i := range fields {
+			itemIndex, itemFlushes := len(s.buf), s.flushes
+			s.buf = seps.appendItem(s.buf, items)
+			s.buf = append(s.buf, fields[i].Key...)
+			valIndex := len(s.buf)
+			if err := encoders[i](s, unsafe.Add(v, fields[i].Offset)); err != nil {
+				return err
+			}
+			if s.flushes == itemFlushes && len(s.buf) == valIndex {
+				s.buf = s.buf[:itemIndex]
+				continue
+			}
+			items++
+			if err := seps.flush(s, start, flushes, items); err != nil {
+				return err
+			}
+		}
+		seps.appendClose(s, '}', items, start, flushes)
+		return nil
+	}, true
+}
+
+func sliceStreamEncoder(deep, indent uint32, t reflect.Type, flags Flags) (streamEncoder, bool) {
+	elem := t.Elem()
+	if elem.Kind() == reflect.Uint8 && !tImplementsAny(elem) && !flags.Has(BytesArray) {
+		return nil, false
+	}
+	omitEmpty := flags.Has(OmitEmpty)
+	encodeItems := streamItemsEncoder(deep, indent, flags, elem, true)
+	return func(s *encodeStream, v unsafe.Pointer) error {
+		h := (*zgo.Slice)(v)
+		if h == nil || h.Len == 0 {
+			if !omitEmpty {
+				s.buf = append(s.buf, '[', ']')
+			}
+			return nil
+		}
+		return encodeItems(s, h.Data, h.Len)
+	}, true
+}
+
+func arrayStreamEncoder(deep, indent uint32, t reflect.Type, flags Flags, ifaceIndir bool) (streamEncoder, bool) {
+	elem := t.Elem()
+	if elem.Kind() == reflect.Uint8 && !tImplementsAny(elem) && flags.Has(bytesStringFlags) {
+		return nil, false
+	}
+	arrayLen := uint(t.Len())
+	// array items are stored inline like struct fields
+	encodeItems := streamItemsEncoder(deep, indent, flags, elem, ifaceIndir)
+	return func(s *encodeStream, v unsafe.Pointer) error {
+		return encodeItems(s, v, arrayLen)
+	}, true
+}
+
+// streamItemsEncoder encodes items of slice or array
+func streamItemsEncoder(deep, indent uint32, flags Flags, elem reflect.Type, ifaceIndir bool) func(s *encodeStream, data unsafe.Pointer, count uint) error {
+	elemSize := uint(elem.Size())
+	elemEncoder := createStreamItemEncoder(deep, indent+1, flags.Exclude(OmitEmpty), elem, ifaceIndir)
+	seps := newStreamSeps(indent, flags)
+	return func(s *encodeStream, data unsafe.Pointer, count uint) error {
+		start, flushes := len(s.buf), s.flushes
+		s.buf = append(s.buf, '[')
+		items := 0
+		for i := range count {
+			itemIndex, itemFlushes := len(s.buf), s.flushes
+			s.buf = seps.appendItem(s.buf, items)
+			valIndex := len(s.buf)
+			if err := elemEncoder(s, unsafe.Add(data, elemSize*i)); err != nil {
+				return err
+			}
+			if s.flushes == itemFlushes && len(s.buf) == valIndex {
+				s.buf = s.buf[:itemIndex]
+				continue
+			}
+			items++
+			if err := seps.flush(s, start, flushes, items); err != nil {
+				return err
+			}
+		}
+		seps.appendClose(s, ']', items, start, flushes)
+		return nil
+	}
+}
+
+type mapStreamItem struct {
+	keyStart int
+	keyEnd   int
+	elem     unsafe.Pointer
+}
+
+// mapStreamSortBuf keeps encoded keys only, values are encoded after sorting
+type mapStreamSortBuf struct {
+	Keys  []byte
+	Items []mapStreamItem
+}
+
+var mapStreamSortBufPool = sync.Pool{New: func() any { return new(mapStreamSortBuf) }}
+
+func (p *mapStreamSortBuf) release() {
+	clear(p.Items)
+	p.Items = p.Items[:0]
+	p.Keys = p.Keys[:0]
+	mapStreamSortBufPool.Put(p)
+}
+
+func mapStreamEncoder(deep, indent uint32, t reflect.Type, flags Flags, ifaceIndir bool) streamEncoder {
+	omitEmpty := flags.Has(OmitEmpty)
+	needQuotes := flags.Has(NeedQuotes)
+	sortKeys := flags.Has(SortMapKeys)
+
+	encodeKey := createItemTypeEncoder(deep, indent+1, (flags | NeedQuotes), t.Key())
+	encodeVal := createStreamItemEncoder(deep, indent+1, flags.Exclude(OmitEmpty), t.Elem(), true)
+	getIterator := zgo.NewMapIteratorFromRType(t)
+	seps := newStreamSeps(indent, flags)
+
+	// encodeValue writes value of item with already written key,
+	// item is trimmed if value is empty
+	encodeValue := func(s *encodeStream, elem unsafe.Pointer, itemIndex, itemFlushes int) (bool, error) {
+		s.buf = append(s.buf, seps.colon...)
+		valIndex := len(s.buf)
+		if err := encodeVal(s, elem); err != nil {
+			return false, err
+		}
+		if s.flushes == itemFlushes && len(s.buf) == valIndex {
+			s.buf = s.buf[:itemIndex]
+			return false, nil
+		}
+		return true, nil
+	}
+
+	return func(s *encodeStream, v unsafe.Pointer) (err error) {
+		if ifaceIndir {
+			v = *(*unsafe.Pointer)(v)
+			if v == nil && needQuotes {
+				s.buf = append(s.buf, '"', '"')
+				return nil
+			}
+		}
+		it, count := getIterator(v)
+		if it == nil {
+			if !omitEmpty {
+				s.buf = append(s.buf, 'n', 'u', 'l', 'l')
+			}
+			return nil
+		}
+		if count == 0 {
+			it.Release()
+			s.buf = append(s.buf, '{', '}')
+			return nil
+		}
+
+		start, flushes := len(s.buf), s.flushes
+		s.buf = append(s.buf, '{')
+		items := 0
+
+		if !sortKeys {
+			for range count {
+				itemIndex, itemFlushes := len(s.buf), s.flushes
+				s.buf = seps.appendItem(s.buf, items)
+				keyIndex := len(s.buf)
+				s.buf, err = encodeKey(s.buf, it.Key)
+				if err != nil {
+					it.Release()
+					return err
+				}
+				if keyIndex == len(s.buf) {
+					s.buf = s.buf[:itemIndex]
+					it.Next()
+					continue
+				}
+				written, err := encodeValue(s, it.Elem, itemIndex, itemFlushes)
+				if err == nil && written {
+					items++
+					err = seps.flush(s, start, flushes, items)
+				}
+				if err != nil {
+					it.Release()
+					return err
+				}
+				it.Next()
+			}
+			it.Release()
+			seps.appendClose(s, '}', items, start, flushes)
+			return nil
+		}
+
+		buf := mapStreamSortBufPool.Get().(*mapStreamSortBuf)
+		buf.Items = slices.Grow(buf.Items, count)
+		for range count {
+			keyStart := len(buf.Keys)
+			buf.Keys, err = encodeKey(buf.Keys, it.Key)
+			if err != nil {
+				it.Release()
+				buf.release()
+				return err
+			}
+			if keyStart != len(buf.Keys) {
+				buf.Items = append(buf.Items, mapStreamItem{keyStart, len(buf.Keys), it.Elem})
+			}
+			it.Next()
+		}
+		it.Release()
+
+		keys := buf.Keys
+		slices.SortFunc(buf.Items, func(a, b mapStreamItem) int {
+			return bytes.Compare(keys[a.keyStart:a.keyEnd], keys[b.keyStart:b.keyEnd])
+		})
+		for _, item := range buf.Items {
+			itemIndex, itemFlushes := len(s.buf), s.flushes
+			s.buf = seps.appendItem(s.buf, items)
+			s.buf = append(s.buf, keys[item.keyStart:item.keyEnd]...)
+			written, err := encodeValue(s, item.elem, itemIndex, itemFlushes)
+			if err == nil && written {
+				items++
+				err = seps.flush(s, start, flushes, items)
+			}
+			if err != nil {
+				buf.release()
+				return err
+			}
+		}
+		buf.release()
+		seps.appendClose(s, '}', items, start, flushes)
+		return nil
+	}
+}
@@ -0,0 +1,201 @@
+package jessy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+type chunksWriter struct {
+	bytes.Buffer
+	chunks []int
+}
+
+func (w *chunksWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, len(p))
+	return w.Buffer.Write(p)
+}
+
+type failWriter struct{ n int }
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n--; w.n < 0 {
+		return 0, errors.New("closed")
+	}
+	return len(p), nil
+}
+
+func TestEncoderFlushThreshold(t *testing.T) {
+	type Base struct {
+		ID   int    `json:"id"`
+		Kind string `json:"kind,omitempty"`
+	}
+	type Item struct {
+		Base
+		Name  string            `json:"name"`
+		Tags  []string          `json:"tags,omitempty"`
+		Attrs map[string]any    `json:"attrs"`
+		Next  *Item             `json:"next,omitempty"`
+		Raw   RawMessage        `json:"raw,omitempty"`
+		Hash  []byte            `json:"hash"`
+		Pair  [2]map[string]int `json:"pair"`
+		At    time.Time         `json:"at"`
+	}
+	type Export struct {
+		Title string         `json:"title"`
+		Items []*Item        `json:"items"`
+		Index map[int][]int  `json:"index"`
+		Any   any            `json:"any"`
+		Empty []Item         `json:"empty,omitempty"`
+		Skip  map[string]int `json:"skip,omitempty"`
+	}
+
+	v := Export{Title: "export", Index: map[int][]int{}, Any: []any{1, "a", map[string]any{"b": []int{2}}}}
+	for i := range 300 {
+		item := &Item{
+			Base:  Base{ID: i},
+			Name:  fmt.Sprint("item-", i),
+			Attrs: map[string]any{"x": i, "y": []int{i, i + 1}, "z": nil},
+			Hash:  []byte{byte(i)},
+			Pair:  [2]map[string]int{{"a": i}, nil},
+			At:    time.Unix(int64(i), 0).UTC(),
+		}
+		if i%3 == 0 {
+			item.Tags = []string{"a", "b"}
+			item.Next = &Item{Name: "next", Raw: RawMessage(`{"r": 1}`)}
+		}
+		v.Items = append(v.Items, item)
+		v.Index[i] = []int{i}
+	}
+
+	styles := []func(e *Encoder){
+		func(e *Encoder) {},
+		func(e *Encoder) { e.SetPrettyFlags(true) },
+		func(e *Encoder) { e.SetIndent("> ", "  ") },
+		func(e *Encoder) { e.SetFlags(EncodeStandard | IndentStyleWidth("", "  ", 40)) },
+	}
+	for i, style := range styles {
+		var expected bytes.Buffer
+		enc := NewEncoder(&expected)
+		style(enc)
+		require.NoError(t, enc.Encode(v))
+
+		var w chunksWriter
+		enc = NewEncoder(&w)
+		style(enc)
+		enc.SetFlushThreshold(1024)
+		require.NoError(t, enc.Encode(v))
+		require.Equal(t, expected.String(), w.String())
+
+		if len(w.chunks) < expected.Len()/2048 {
+			t.Fatalf("style %d: %d chunks of %d bytes", i, len(w.chunks), expected.Len())
+		}
+		for _, n := range w.chunks {
+			if n > 2048 {
+				t.Fatalf("style %d: chunk of %d bytes", i, n)
+			}
+		}
+	}
+
+	var w chunksWriter
+	enc := NewEncoder(&w)
+	enc.SetFlushThreshold(16)
+	require.NoError(t, enc.Encode(nil))
+	require.NoError(t, enc.Encode(map[string]int{}))
+	require.NoError(t, enc.Encode([]int{1, 2, 3}))
+	require.Equal(t, "null\n{}\n[1,2,3]\n", w.String())
+
+	enc = NewEncoder(&failWriter{n: 2})
+	enc.SetFlushThreshold(16)
+	require.NotEqual(t, nil, enc.Encode(v))
+}
+
+type streamText string
+
+func (s streamText) MarshalText() ([]byte, error) { return []byte("<" + s + ">"), nil }
+
+func TestEncoderFlushThresholdFlags(t *testing.T) {
+	type Inner struct {
+		N     int64              `json:"n"`
+		F     float64            `json:"f"`
+		S     string             `json:"s,omitempty"`
+		Q     int                `json:"q,string"`
+		Text  streamText         `json:"text"`
+		Keys  map[streamText]int `json:"keys"`
+		Bytes []byte             `json:"bytes"`
+		Arr   [3]byte            `json:"arr"`
+	}
+	type Value struct {
+		Inner
+		Strings []string             `json:"strings"`
+		Map     map[string]any       `json:"map"`
+		Ints    map[int]*Inner       `json:"ints"`
+		Items   []Inner              `json:"items"`
+		Ptrs    [2]*Inner            `json:"ptrs"`
+		Raw     RawMessage           `json:"raw"`
+		Seq     func(func(int) bool) `json:"seq"`
+		Func    func()               `json:"func,omitempty"`
+		Empty   []int                `json:"empty,omitempty"`
+	}
+	inner := func(i int) Inner {
+		return Inner{
+			N: 1<<60 + int64(i), F: 1.0 / float64(i+3), S: fmt.Sprint("<a/b>é ", i),
+			Q: i, Text: "t", Keys: map[streamText]int{"b": i, "a": 1},
+			Bytes: []byte{byte(i), 0xff}, Arr: [3]byte{1, byte(i)},
+		}
+	}
+	v := Value{
+		Inner:   inner(0),
+		Strings: []string{"x\xffy", "&", ""},
+		Map:     map[string]any{"z": 1.5e21, "y": []any{nil, true, "/"}, "x": map[string]any{}},
+		Ints:    map[int]*Inner{},
+		Raw:     RawMessage(` { "r" : [ 1 , "<>" ] } `),
+		Seq: func(yield func(int) bool) {
+			for i := range 20 {
+				if !yield(i) {
+					return
+				}
+			}
+		},
+	}
+	for i := range 20 {
+		item := inner(i)
+		v.Items = append(v.Items, item)
+		v.Ints[i] = &item
+	}
+	v.Ptrs[1] = &v.Items[3]
+
+	options := []Flags{
+		EscapeHTML, ValidateString, CompactMarshaler, OmitEmpty,
+		FloatNoExponent, QuoteUnsafeInt64, BytesHex, EscapeUnicode, EscapeSlash, KeepInvalidUTF8, SkipUnsupported,
+	}
+	styles := []Flags{0, PrettySpaces, IndentStyleWidth("", "  ", 60)}
+	for mask := range 1 << len(options) {
+		// map order is random, only sorted maps are comparable
+		flags := SortMapKeys
+		for i, flag := range options {
+			if mask&(1<<i) != 0 {
+				flags |= flag
+			}
+		}
+		for _, style := range styles {
+			flags := flags | style
+			expected, expectedErr := MarshalFlags(v, flags)
+
+			var w chunksWriter
+			enc := NewEncoderWithFlags(&w, flags)
+			enc.SetFlushThreshold(64)
+			err := enc.Encode(v)
+			if expectedErr != nil {
+				require.NotEqual(t, nil, err)
+				continue
+			}
+			require.NoError(t, err)
+			require.Equal(t, string(expected)+"\n", w.String())
+		}
+	}
+}
@@ -44,6 +44,10 @@ func stringEncoder(t reflect.Type, flags Flags) UnsafeEncoder {
 		}
 	}
 
+	if flags.Has(EscapeUnicode|EscapeSlash) || (needValidate && flags.Has(KeepInvalidUTF8)) {
+		return stringEscapeEncoder(flags)
+	}
+
 	if omitEmpty {
 		if needValidate {
 			return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
@@ -90,3 +94,26 @@ func stringEncoder(t reflect.Type, flags Flags) UnsafeEncoder {
 		return dst, nil
 	}
 }
+
+// stringEscapeEncoder encodes strings with extended escaping options
+func stringEscapeEncoder(flags Flags) UnsafeEncoder {
+	escapeFlags := flags.escapeFlags()
+	if flags.Has(OmitEmpty) {
+		return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+			h := (*zgo.String)(v)
+			if h.Len == 0 {
+				return dst, nil
+			}
+			data := unsafe.Slice(h.Data, h.Len)
+			return zstr.AppendQuotedStringFlags(dst, data, escapeFlags), nil
+		}
+	}
+	return func(dst []byte, v unsafe.Pointer) ([]byte, error) {
+		h := (*zgo.String)(v)
+		if h.Len == 0 {
+			return append(dst, '"', '"'), nil
+		}
+		data := unsafe.Slice(h.Data, h.Len)
+		return zstr.AppendQuotedStringFlags(dst, data, escapeFlags), nil
+	}
+}
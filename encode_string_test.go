@@ -0,0 +1,53 @@
+package jessy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestMarshalEscapeModes(t *testing.T) {
+	type Page struct {
+		Title string            `json:"title"`
+		URL   string            `json:"url"`
+		Raw   string            `json:"raw"`
+		Tags  map[string]string `json:"tags"`
+	}
+	v := Page{
+		Title: "Привет 😀 <b>",
+		URL:   "https://example.com/a",
+		Raw:   "bad\xffbyte",
+		Tags:  map[string]string{"ключ": "é"},
+	}
+
+	data, err := MarshalFlags(v, EncodeStandard|EscapeUnicode)
+	require.NoError(t, err)
+	require.Equal(t, `{"raw":"bad\ufffdbyte","tags":{"\u043a\u043b\u044e\u0447":"\u00e9"},"title":"\u041f\u0440\u0438\u0432\u0435\u0442 \ud83d\ude00 \u003cb\u003e","url":"https://example.com/a"}`, string(data))
+
+	var decoded Page
+	require.NoError(t, Unmarshal(data, &decoded))
+	require.Equal(t, "Привет 😀 <b>", decoded.Title)
+
+	data, err = MarshalFlags(v, EscapeSlash|KeepInvalidUTF8|ValidateString)
+	require.NoError(t, err)
+	require.Equal(t, `{"raw":"bad`+"\xff"+`byte","tags":{"ключ":"é"},"title":"Привет 😀 <b>","url":"https:\/\/example.com\/a"}`, string(data))
+}
+
+func TestCompactEscapeModes(t *testing.T) {
+	src := []byte(`{ "a/b" : "\u00e9\"😀é", "n" : [1, 2] }`)
+
+	var buf bytes.Buffer
+	require.NoError(t, CompactFlags(&buf, src, EscapeUnicode|EscapeSlash))
+	require.Equal(t, `{"a\/b":"\u00e9\"\ud83d\ude00\u00e9","n":[1,2]}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, HTMLEscapeFlags(&buf, []byte(`{"x": "<é>"}`), EscapeUnicode))
+	require.Equal(t, `{"x": "\u003c\u00e9\u003e"}`, buf.String())
+}
+
+func TestMarshalEscapeAfterEscape(t *testing.T) {
+	data, err := Marshal([]string{"x\né", "é\n", "\t\"<\u2028é", "a\xffb\nc"})
+	require.NoError(t, err)
+	require.Equal(t, `["x\né","é\n","\t\"\u003c\u2028é","a\ufffdb\nc"]`, string(data))
+}
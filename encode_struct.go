@@ -3,8 +3,11 @@ package jessy
 import (
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"unsafe"
+
+	"github.com/avpetkun/jessy-go/zstr"
 )
 
 type StructField struct {
@@ -12,6 +15,19 @@ type StructField struct {
 	KeyLen  int
 	Offset  uintptr
 	Encoder UnsafeEncoder
+
+	// type and flags of field for stream encoders
+	typ    reflect.Type
+	flags  Flags
+	tagged bool // name is set by json tag
+}
+
+// name returns unquoted key of field, keys of embedded structs are empty
+func (f *StructField) name() string {
+	if f.KeyLen == 0 {
+		return ""
+	}
+	return f.Key[1:strings.LastIndex(f.Key, `":`)]
 }
 
 func getStructFields(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir, embedded bool) (fields []StructField) {
@@ -28,24 +44,16 @@ func getStructFields(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndi
 			continue
 		}
 
-		parts := strings.Split(f.Tag.Get("json"), ",")
-		name := parts[0]
+		name, options, _ := strings.Cut(f.Tag.Get("json"), ",")
 		if name == "-" {
 			continue
 		}
-		if name == "" {
+		tagged := name != ""
+		if !tagged {
 			name = f.Name
 		}
 
-		fieldFlags := flags
-		for _, action := range parts[1:] {
-			switch action {
-			case "omitempty":
-				fieldFlags |= OmitEmpty
-			case "string":
-				fieldFlags |= NeedQuotes
-			}
-		}
+		fieldFlags := parseFieldOptions(flags, options)
 
 		fieldEncoder := createTypeEncoder(deep, indent, fieldFlags, f.Type, ifaceIndir, anonymousStruct)
 
@@ -54,9 +62,14 @@ func getStructFields(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndi
 				KeyLen:  0,
 				Offset:  f.Offset,
 				Encoder: fieldEncoder,
+				typ:     f.Type,
+				flags:   fieldFlags,
 			})
 		} else {
 			key := `"` + name + `":`
+			if flags.Has(EscapeUnicode | EscapeSlash) {
+				key = string(zstr.AppendQuotedStringFlags(nil, []byte(name), flags.escapeFlags())) + ":"
+			}
 			if flags.Has(PrettySpaces) {
 				key += " "
 			}
@@ -65,10 +78,14 @@ func getStructFields(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndi
 				KeyLen:  len(key),
 				Offset:  f.Offset,
 				Encoder: fieldEncoder,
+				typ:     f.Type,
+				flags:   fieldFlags,
+				tagged:  tagged,
 			})
 		}
 	}
-	sort.Slice(fields, func(i, j int) bool {
+	// stable to keep embedded structs without keys in order of declaration
+	sort.SliceStable(fields, func(i, j int) bool {
 		return fields[i].Key < fields[j].Key
 	})
 	return
@@ -85,7 +102,7 @@ func structEncoder(deep, indent uint32, flags Flags, t reflect.Type, ifaceIndir,
 	}
 
 	if flags.Has(PrettySpaces) {
-		return structEncoderPretty(indent, fields, embedded)
+		return structEncoderPretty(indent, flags, fields, embedded)
 	}
 	return structEncoderMinimal(fields, embedded)
 }
@@ -97,14 +114,15 @@ func nopStructEncoder(dst []byte, value unsafe.Pointer) ([]byte, error) {
 	return append(dst, '{', '}'), nil
 }
 
-func structEncoderPretty(indent uint32, fields []StructField, embedded bool) UnsafeEncoder {
-	deepSpace0 := getIndent(indent)
+func structEncoderPretty(indent uint32, flags Flags, fields []StructField, embedded bool) UnsafeEncoder {
+	deepSpace0 := getIndent(flags, indent)
 	if embedded {
 		return func(dst []byte, value unsafe.Pointer) ([]byte, error) {
 			var err error
-			var was uint32
+			var was bool
 			for i := range fields {
-				if was != 0 {
+				keyIndex := len(dst)
+				if was {
 					dst = append(dst, deepSpace0...)
 				}
 				dst = append(dst, fields[i].Key...)
@@ -114,23 +132,27 @@ func structEncoderPretty(indent uint32, fields []StructField, embedded bool) Uns
 					return dst, err
 				}
 				if len(dst) == dstLen {
-					dst = dst[:dstLen-fields[i].KeyLen-int(was)]
+					dst = dst[:keyIndex]
 				} else {
 					dst = append(dst, ',', '\n')
-					was = indent
+					was = true
 				}
 			}
-			if i := len(dst); i != 0 && dst[i-1] == '\n' {
-				dst = dst[:i-2]
+			if was {
+				dst = dst[:len(dst)-2]
 			}
 			return dst, nil
 		}
 	}
-	deepSpace1 := getIndent(indent + 1)
+	deepSpace1 := getIndent(flags, indent+1)
+	width := getIndentStyle(flags).width
 	return func(dst []byte, value unsafe.Pointer) ([]byte, error) {
+		start := len(dst)
 		dst = append(dst, '{', '\n')
+		dstInitLen := len(dst)
 		var err error
 		for i := range fields {
+			keyIndex := len(dst)
 			dst = append(dst, deepSpace1...)
 			dst = append(dst, fields[i].Key...)
 			dstLen := len(dst)
@@ -139,17 +161,22 @@ func structEncoderPretty(indent uint32, fields []StructField, embedded bool) Uns
 				return dst, err
 			}
 			if len(dst) == dstLen {
-				dst = dst[:dstLen-fields[i].KeyLen-1-int(indent)]
+				dst = dst[:keyIndex]
 			} else {
 				dst = append(dst, ',', '\n')
 			}
 		}
-		if i := len(dst) - 2; dst[i] == ',' {
-			dst = dst[:i]
+		if i := len(dst); i == dstInitLen {
+			dst[i-1] = '}'
+			return dst, nil
 		}
+		dst = dst[:len(dst)-2]
 		dst = append(dst, '\n')
 		dst = append(dst, deepSpace0...)
 		dst = append(dst, '}')
+		if width != 0 {
+			dst = zstr.CollapseIndent(dst, start, width, len(deepSpace1), len(deepSpace0))
+		}
 		return dst, nil
 	}
 }
@@ -201,3 +228,62 @@ func structEncoderMinimal(fields []StructField, embedded bool) UnsafeEncoder {
 		return dst, nil
 	}
 }
+
+// parseFieldOptions applies comma separated options of field tag
+func parseFieldOptions(flags Flags, options string) Flags {
+	for options != "" {
+		var option string
+		option, options, _ = strings.Cut(options, ",")
+		switch option {
+		case "omitempty":
+			flags |= OmitEmpty
+		case "string":
+			flags |= NeedQuotes
+		default:
+			flags = parseFieldOption(flags, option)
+		}
+	}
+	return flags
+}
+
+// parseFieldOption applies extended field tag options, unknown options are ignored
+func parseFieldOption(flags Flags, option string) Flags {
+	name, value, _ := strings.Cut(option, "=")
+	switch name {
+	case "int64":
+		switch value {
+		case "string":
+			return flags.Exclude(QuoteUnsafeInt64) | QuoteInt64
+		case "safe":
+			return flags.Exclude(QuoteInt64) | QuoteUnsafeInt64
+		}
+	case "bytes":
+		format := Flags(0)
+		switch value {
+		case "base64":
+			format = BytesBase64
+		case "base64url":
+			format = BytesBase64URL
+		case "base64raw":
+			format = BytesBase64Raw
+		case "hex":
+			format = BytesHex
+		case "array":
+			format = BytesArray
+		default:
+			return flags
+		}
+		return flags.Exclude(bytesFormatFlags) | format
+	case "noexp":
+		return flags | FloatNoExponent
+	case "precision":
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 0xff {
+			return flags.withFloatFormat(FloatPrecision(n))
+		}
+	case "digits":
+		if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= 0xff {
+			return flags.withFloatFormat(FloatDigits(n))
+		}
+	}
+	return flags
+}
@@ -1,13 +1,115 @@
 package jessy
 
-import "reflect"
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
 
-func getIndent(n uint32) []byte {
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = '\t'
+type indentStyle struct {
+	prefix string
+	indent string
+	width  int // keep containers on one line while it fits, 0 - never
+}
+
+const (
+	// style ids are stored in a byte of flags
+	maxIndentStyles = 0x100
+	// MarshalIndent and Encoder.SetIndent add styles of short strings only
+	// and leave a half of ids to IndentStyle, other indents are applied to compact output
+	maxImplicitIndentStyles = maxIndentStyles / 2
+	maxImplicitIndentLen    = 16
+)
+
+// ErrTooManyIndentStyles is returned by NewIndentStyle when all style ids are used
+var ErrTooManyIndentStyles = errors.New("json: too many indent styles")
+
+var (
+	indentStylesMu  sync.Mutex // serializes adding of styles
+	indentStylesIDs sync.Map   // indentStyle -> Flags
+	// copy on write list indexed by style id,
+	// style id 0 is the default PrettySpaces style
+	indentStyles atomic.Pointer[[]indentStyle]
+)
+
+func init() {
+	styles := []indentStyle{{prefix: "", indent: "\t"}}
+	indentStyles.Store(&styles)
+	indentStylesIDs.Store(styles[0], PrettySpaces)
+}
+
+// IndentStyle returns PrettySpaces flags which indent output with prefix and indent strings
+// in a single pass like MarshalIndent, e.g. IndentStyle("", "  ") for two spaces.
+// Styles are registered for the process lifetime and up to 256 different styles
+// can be used, IndentStyle panics on more, NewIndentStyle returns an error instead
+func IndentStyle(prefix, indent string) Flags {
+	return IndentStyleWidth(prefix, indent, 0)
+}
+
+// IndentStyleWidth is IndentStyle which keeps arrays and objects on one line
+// like [1, 2, 3] while the line fits into width bytes
+func IndentStyleWidth(prefix, indent string, width int) Flags {
+	style, err := NewIndentStyle(prefix, indent, width)
+	if err != nil {
+		panic(err)
+	}
+	return style
+}
+
+// NewIndentStyle is IndentStyleWidth which returns ErrTooManyIndentStyles
+// when all style ids are used instead of panicking
+func NewIndentStyle(prefix, indent string, width int) (Flags, error) {
+	if width < 0 {
+		panic("indent width must be >= 0")
+	}
+	style, ok := indentStyleFlags(indentStyle{prefix, indent, width}, maxIndentStyles)
+	if !ok {
+		return 0, ErrTooManyIndentStyles
 	}
-	return b
+	return style, nil
+}
+
+// implicitIndentStyleFlags returns style of MarshalIndent and Encoder.SetIndent,
+// false is returned if the indent must be applied to compact output
+func implicitIndentStyleFlags(prefix, indent string) (Flags, bool) {
+	limit := maxImplicitIndentStyles
+	if len(prefix)+len(indent) > maxImplicitIndentLen {
+		limit = 0 // only styles added by IndentStyle
+	}
+	return indentStyleFlags(indentStyle{prefix: prefix, indent: indent}, limit)
+}
+
+// indentStyleFlags returns flags of style adding it while there are less than limit styles
+func indentStyleFlags(style indentStyle, limit int) (Flags, bool) {
+	if flags, ok := indentStylesIDs.Load(style); ok {
+		return flags.(Flags), true
+	}
+	indentStylesMu.Lock()
+	defer indentStylesMu.Unlock()
+	if flags, ok := indentStylesIDs.Load(style); ok {
+		return flags.(Flags), true
+	}
+	styles := *indentStyles.Load()
+	if len(styles) >= limit {
+		return 0, false
+	}
+	flags := PrettySpaces | Flags(len(styles))<<indentStyleShift
+	styles = append(styles[:len(styles):len(styles)], style)
+	indentStyles.Store(&styles)
+	indentStylesIDs.Store(style, flags)
+	return flags, true
+}
+
+func getIndentStyle(flags Flags) indentStyle {
+	return (*indentStyles.Load())[(flags&indentStyleMask)>>indentStyleShift]
+}
+
+// getIndent returns line prefix of n nesting level of flags indent style
+func getIndent(flags Flags, n uint32) []byte {
+	style := getIndentStyle(flags)
+	return []byte(style.prefix + strings.Repeat(style.indent, int(n)))
 }
 
 func tReallyImplements(t, interfaceType reflect.Type) bool {
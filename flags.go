@@ -1,7 +1,9 @@
 package jessy
 
+import "github.com/avpetkun/jessy-go/zstr"
+
 // possible values: EscapeHTML, OmitEmpty, NeedQuotes
-type Flags uint32
+type Flags uint64
 
 func (flags Flags) Has(flag Flags) bool {
 	return flags&flag != 0
@@ -25,9 +27,112 @@ const (
 	OmitEmpty
 	NeedQuotes
 
-	encodeFlagsLen
+	// float formatting
+	FloatNoExponent
+	floatFormatFixed
+	floatFormatDigits
+
+	// 64-bit integers formatting (int, int64, uint, uint64, big.Int)
+	QuoteInt64       // always quote, same as `json:",int64=string"`
+	QuoteUnsafeInt64 // quote only outside of JavaScript safe range ±(2^53-1), same as `json:",int64=safe"`
+
+	// []byte and [N]byte formatting, by default []byte is base64 string and [N]byte is array of numbers
+	BytesBase64    // standard base64 string, same as `json:",bytes=base64"`
+	BytesBase64URL // url-safe base64 string, same as `json:",bytes=base64url"`
+	BytesBase64Raw // standard base64 string without padding, same as `json:",bytes=base64raw"`
+	BytesHex       // lowercase hex string, same as `json:",bytes=hex"`
+	BytesArray     // array of numbers, same as `json:",bytes=array"`
+
+	// string escaping, also applied to struct keys and compacted marshalers output
+	EscapeUnicode   // escape every non-ASCII rune as \uXXXX, output is pure ASCII
+	EscapeSlash     // escape / as \/
+	KeepInvalidUTF8 // keep invalid UTF-8 bytes as is instead of replacing them with \ufffd
+
+	// ANSI colored output for terminals, palette is set by SetColorPalette
+	Colorize
+
+	// receiving channels are encoded as arrays of values received until they are closed,
+	// by default channels are not encoded like in encoding/json
+	EncodeChannels
+
+	// fields and items of unsupported types (functions which are not iter.Seq or iter.Seq2,
+	// channels and unsafe pointers) are dropped together with their keys,
+	// by default encoding fails with *UnsupportedTypeError like in encoding/json
+	SkipUnsupported
 
 	// configs
 	EncodeFastest  = 0
 	EncodeStandard = SortMapKeys | EscapeHTML | ValidateString | ValidateTextMarshaler | CompactMarshaler
 )
+
+// float precision is stored in the high byte of flags
+const (
+	floatPrecisionShift = 56
+	floatPrecisionMask  = Flags(0xff) << floatPrecisionShift
+
+	floatFormatFlags = FloatNoExponent | floatFormatFixed | floatFormatDigits
+
+	bytesStringFlags = BytesBase64 | BytesBase64URL | BytesBase64Raw | BytesHex
+	bytesFormatFlags = bytesStringFlags | BytesArray
+
+	escapeStringFlags = EscapeUnicode | EscapeSlash | KeepInvalidUTF8
+)
+
+// indent style id of PrettySpaces is stored in the next byte of flags
+const (
+	indentStyleShift = 48
+	indentStyleMask  = Flags(0xff) << indentStyleShift
+)
+
+// FloatPrecision formats floats with exactly n digits after the decimal point
+// and never uses an exponent, e.g. FloatPrecision(2) encodes 1.5 as 1.50
+//
+// Same as `json:",precision=n"` field tag option
+func FloatPrecision(n int) Flags {
+	if n < 0 || n > 0xff {
+		panic("float precision must be in range [0, 255]")
+	}
+	return floatFormatFixed | Flags(n)<<floatPrecisionShift
+}
+
+// FloatDigits rounds floats to at most n significant digits,
+// e.g. FloatDigits(3) encodes 3.14159 as 3.14
+//
+// Same as `json:",digits=n"` field tag option
+func FloatDigits(n int) Flags {
+	if n < 1 || n > 0xff {
+		panic("float digits must be in range [1, 255]")
+	}
+	return floatFormatDigits | Flags(n)<<floatPrecisionShift
+}
+
+func (flags Flags) floatPrecision() int {
+	return int(flags >> floatPrecisionShift)
+}
+
+// withFloatFormat replaces float precision options, keeps FloatNoExponent
+func (flags Flags) withFloatFormat(format Flags) Flags {
+	return flags.Exclude(floatFormatFixed|floatFormatDigits|floatPrecisionMask) | format
+}
+
+// withIndentStyle replaces indent style, style is a result of IndentStyle
+func (flags Flags) withIndentStyle(style Flags) Flags {
+	return flags.Exclude(indentStyleMask) | style
+}
+
+// escapeFlags converts string escaping options to zstr escape flags
+func (flags Flags) escapeFlags() (f zstr.EscapeFlags) {
+	if flags.Has(EscapeHTML) {
+		f |= zstr.EscapeHTML
+	}
+	if flags.Has(EscapeUnicode) {
+		f |= zstr.EscapeUnicode
+	}
+	if flags.Has(EscapeSlash) {
+		f |= zstr.EscapeSlash
+	}
+	if flags.Has(KeepInvalidUTF8) {
+		f |= zstr.KeepInvalidUTF8
+	}
+	return
+}
@@ -0,0 +1,776 @@
+package jessy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/avpetkun/jessy-go/std"
+	"github.com/avpetkun/jessy-go/zgo"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+// Kind is a kind of JSON value
+type Kind uint8
+
+const (
+	KindInvalid Kind = iota
+	KindNull
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+var kindNames = [...]string{"invalid", "null", "bool", "number", "string", "array", "object"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// kindOfByte is a kind of value by its first byte
+var kindOfByte = [256]Kind{
+	'n': KindNull, 't': KindBool, 'f': KindBool, '"': KindString, '[': KindArray, '{': KindObject,
+	'-': KindNumber, '0': KindNumber, '1': KindNumber, '2': KindNumber, '3': KindNumber,
+	'4': KindNumber, '5': KindNumber, '6': KindNumber, '7': KindNumber, '8': KindNumber, '9': KindNumber,
+}
+
+// iteratorMaxDepth is max nesting of values skipped by Iterator like in encoding/json
+const iteratorMaxDepth = 10000
+
+const iteratorBufferSize = 4096
+
+// Iterator reads JSON values one by one from bytes or reader without reflection.
+// Returned strings and bytes point into the iterator buffer or data
+// and are valid until the next read. The first error is kept and
+// next reads return zero values, so it is checked once by Error
+type Iterator struct {
+	buf    []byte
+	pos    int
+	mark   int   // start of raw value kept on buffer refill or -1
+	offset int64 // bytes of reader dropped before buf
+
+	r   io.Reader
+	eof bool
+	err error
+
+	reads int // completed reads to skip values unread by callbacks
+
+	str []byte // unescaped strings
+	key []byte // keys of ReadObject
+}
+
+// NewIterator returns Iterator reading data
+func NewIterator(data []byte) *Iterator {
+	it := new(Iterator)
+	it.Reset(data)
+	return it
+}
+
+// NewIteratorReader returns Iterator reading r with buffer of size bytes,
+// the buffer grows only to hold the longest string or number or raw value
+func NewIteratorReader(r io.Reader, size int) *Iterator {
+	if size <= 0 {
+		size = iteratorBufferSize
+	}
+	return &Iterator{buf: make([]byte, 0, size), mark: -1, r: r}
+}
+
+// Reset starts reading of data
+func (it *Iterator) Reset(data []byte) {
+	*it = Iterator{buf: data, mark: -1, str: it.str[:0], key: it.key[:0]}
+}
+
+// ResetReader starts reading of r
+func (it *Iterator) ResetReader(r io.Reader) {
+	buf := it.buf[:0]
+	if it.r == nil {
+		// do not overwrite data of Reset
+		buf = make([]byte, 0, max(cap(buf), iteratorBufferSize))
+	}
+	*it = Iterator{buf: buf, mark: -1, r: r, str: it.str[:0], key: it.key[:0]}
+}
+
+// Error returns the first error of reading
+func (it *Iterator) Error() error {
+	return it.err
+}
+
+// InputOffset returns offset of input after the last read value
+func (it *Iterator) InputOffset() int64 {
+	return it.offset + int64(it.pos)
+}
+
+// more reads next data of reader keeping buffer from pos or mark,
+// it returns false at the end of input
+func (it *Iterator) more() bool {
+	if it.r == nil || it.eof || it.err != nil {
+		return false
+	}
+	keep := it.pos
+	if it.mark >= 0 && it.mark < keep {
+		keep = it.mark
+	}
+	if keep > 0 {
+		it.buf = it.buf[:copy(it.buf, it.buf[keep:])]
+		it.pos -= keep
+		if it.mark >= 0 {
+			it.mark -= keep
+		}
+		it.offset += int64(keep)
+	}
+	if len(it.buf) == cap(it.buf) {
+		it.buf = slices.Grow(it.buf, max(cap(it.buf), iteratorBufferSize))
+	}
+	for {
+		n, err := it.r.Read(it.buf[len(it.buf):cap(it.buf)])
+		it.buf = it.buf[:len(it.buf)+n]
+		if err != nil {
+			if err == io.EOF {
+				it.eof = true
+			} else {
+				it.err = err
+			}
+			return n > 0
+		}
+		if n > 0 {
+			return true
+		}
+	}
+}
+
+// at returns byte at n after pos reading more input if needed
+func (it *Iterator) at(n int) (byte, bool) {
+	for it.pos+n >= len(it.buf) {
+		if !it.more() {
+			return 0, false
+		}
+	}
+	return it.buf[it.pos+n], true
+}
+
+// peek skips spaces and returns next byte, zero at the end of input or on error.
+// NUL byte is never valid outside of strings, so it fails like the end of input can't
+func (it *Iterator) peek() byte {
+	if it.err != nil {
+		return 0
+	}
+	for {
+		for ; it.pos < len(it.buf); it.pos++ {
+			switch c := it.buf[it.pos]; c {
+			case ' ', '\t', '\n', '\r':
+			case 0:
+				it.fail(`invalid character '\x00' looking for beginning of value`)
+				return 0
+			default:
+				return c
+			}
+		}
+		if !it.more() {
+			return 0
+		}
+	}
+}
+
+func (it *Iterator) fail(msg string) {
+	if it.err == nil {
+		it.err = std.NewSyntaxError(msg, it.InputOffset())
+	}
+}
+
+// failAt reports unexpected byte c at pos, zero c is the end of input
+func (it *Iterator) failAt(c byte, context string) {
+	if c == 0 && it.pos >= len(it.buf) {
+		it.fail("unexpected end of JSON input")
+		return
+	}
+	it.fail(fmt.Sprintf("invalid character %q %s", c, context))
+}
+
+// Next returns kind of the next value without reading it,
+// KindInvalid is returned at the end of input or on error
+func (it *Iterator) Next() Kind {
+	return kindOfByte[it.peek()]
+}
+
+// ReadNull reads null and returns true if the next value is null
+func (it *Iterator) ReadNull() bool {
+	if it.peek() != 'n' {
+		return false
+	}
+	if it.expect("null") {
+		it.reads++
+		return true
+	}
+	return false
+}
+
+func (it *Iterator) ReadBool() bool {
+	switch c := it.peek(); c {
+	case 't':
+		if it.expect("true") {
+			it.reads++
+			return true
+		}
+	case 'f':
+		if it.expect("false") {
+			it.reads++
+		}
+	default:
+		it.failAt(c, "looking for beginning of bool")
+	}
+	return false
+}
+
+// expect reads literal
+func (it *Iterator) expect(lit string) bool {
+	for i := range len(lit) {
+		if c, ok := it.at(i); c != lit[i] {
+			if ok {
+				it.pos += i
+			} else {
+				it.pos = len(it.buf)
+			}
+			it.failAt(c, "in literal "+lit+" (expecting "+strconv.QuoteRune(rune(lit[i]))+")")
+			return false
+		}
+	}
+	if !it.delimited(len(lit)) {
+		return false
+	}
+	it.pos += len(lit)
+	return true
+}
+
+// delimited checks that number or literal of n bytes ends by space,
+// separator or the end of input
+func (it *Iterator) delimited(n int) bool {
+	c, ok := it.at(n)
+	if !ok {
+		return true
+	}
+	switch c {
+	case ' ', '\t', '\n', '\r', ',', ':', ']', '}':
+		return true
+	}
+	it.pos += n
+	it.failAt(c, "after value")
+	return false
+}
+
+// ReadString reads string value into new string
+func (it *Iterator) ReadString() string {
+	return string(it.ReadStringBytes())
+}
+
+// ReadStringBytes reads string value, escaped strings are decoded
+// into the iterator buffer, the result is valid until the next read
+func (it *Iterator) ReadStringBytes() []byte {
+	if c := it.peek(); c != '"' {
+		it.failAt(c, "looking for beginning of string")
+		return nil
+	}
+	s := it.scanString(&it.str)
+	if it.err != nil {
+		return nil
+	}
+	it.reads++
+	return s
+}
+
+// scanString reads string at pos, escaped string is decoded into scratch
+// or not decoded at all if scratch is nil
+func (it *Iterator) scanString(scratch *[]byte) []byte {
+	n := 1
+	escaped := false
+	for {
+		c, ok := it.at(n)
+		if !ok {
+			it.pos = len(it.buf)
+			it.failAt(0, "")
+			return nil
+		}
+		if c == '"' {
+			break
+		}
+		if c < ' ' {
+			it.pos += n
+			it.failAt(c, "in string literal")
+			return nil
+		}
+		if c == '\\' {
+			escaped = true
+			n++
+		}
+		n++
+	}
+	s := it.buf[it.pos+1 : it.pos+n]
+	if escaped && scratch != nil {
+		var ok bool
+		if *scratch, ok = zstr.AppendUnescapedString((*scratch)[:0], s); !ok {
+			it.pos++
+			it.fail("invalid escape in string literal")
+			return nil
+		}
+		s = *scratch
+	}
+	it.pos += n + 1
+	return s
+}
+
+// ReadNumber reads number value as is
+func (it *Iterator) ReadNumber() []byte {
+	c := it.peek()
+	if c != '-' && (c < '0' || c > '9') {
+		it.failAt(c, "looking for beginning of number")
+		return nil
+	}
+	num := it.scanNumber()
+	if it.err != nil {
+		return nil
+	}
+	it.reads++
+	return num
+}
+
+// scanNumber reads number at pos by JSON grammar
+func (it *Iterator) scanNumber() []byte {
+	n := 0
+	c, _ := it.at(0)
+	if c == '-' {
+		n++
+		c, _ = it.at(n)
+	}
+	switch {
+	case c == '0':
+		n++
+	case '1' <= c && c <= '9':
+		n = it.scanDigits(n + 1)
+	default:
+		it.pos += n
+		it.failAt(c, "in numeric literal")
+		return nil
+	}
+	if c, _ = it.at(n); c == '.' {
+		if c, _ = it.at(n + 1); c < '0' || c > '9' {
+			it.pos += n + 1
+			it.failAt(c, "after decimal point in numeric literal")
+			return nil
+		}
+		n = it.scanDigits(n + 1)
+		c, _ = it.at(n)
+	}
+	if c == 'e' || c == 'E' {
+		n++
+		if c, _ = it.at(n); c == '+' || c == '-' {
+			n++
+			c, _ = it.at(n)
+		}
+		if c < '0' || c > '9' {
+			it.pos += n
+			it.failAt(c, "in exponent of numeric literal")
+			return nil
+		}
+		n = it.scanDigits(n)
+	}
+	if !it.delimited(n) {
+		return nil
+	}
+	num := it.buf[it.pos : it.pos+n]
+	it.pos += n
+	return num
+}
+
+func (it *Iterator) scanDigits(n int) int {
+	for {
+		if c, ok := it.at(n); !ok || c < '0' || c > '9' {
+			return n
+		}
+		n++
+	}
+}
+
+func (it *Iterator) failNumber(num []byte, typ string, err error) {
+	if it.err == nil {
+		it.err = fmt.Errorf("json: cannot read number %s as %s at offset %d: %w", num, typ, it.InputOffset(), err)
+	}
+}
+
+func (it *Iterator) ReadInt() int {
+	return int(it.readInt(strconv.IntSize, "int"))
+}
+
+func (it *Iterator) ReadInt8() int8 {
+	return int8(it.readInt(8, "int8"))
+}
+
+func (it *Iterator) ReadInt16() int16 {
+	return int16(it.readInt(16, "int16"))
+}
+
+func (it *Iterator) ReadInt32() int32 {
+	return int32(it.readInt(32, "int32"))
+}
+
+func (it *Iterator) ReadInt64() int64 {
+	return it.readInt(64, "int64")
+}
+
+func (it *Iterator) readInt(bits int, typ string) int64 {
+	num := it.ReadNumber()
+	if num == nil {
+		return 0
+	}
+	v, err := zstr.ParseInt64(num)
+	if err == nil && bits < 64 && (v < -1<<(bits-1) || v >= 1<<(bits-1)) {
+		err = strconv.ErrRange
+	}
+	if err != nil {
+		it.failNumber(num, typ, err)
+		return 0
+	}
+	return v
+}
+
+func (it *Iterator) ReadUint() uint {
+	return uint(it.readUint(strconv.IntSize, "uint"))
+}
+
+func (it *Iterator) ReadUint8() uint8 {
+	return uint8(it.readUint(8, "uint8"))
+}
+
+func (it *Iterator) ReadUint16() uint16 {
+	return uint16(it.readUint(16, "uint16"))
+}
+
+func (it *Iterator) ReadUint32() uint32 {
+	return uint32(it.readUint(32, "uint32"))
+}
+
+func (it *Iterator) ReadUint64() uint64 {
+	return it.readUint(64, "uint64")
+}
+
+func (it *Iterator) readUint(bits int, typ string) uint64 {
+	num := it.ReadNumber()
+	if num == nil {
+		return 0
+	}
+	v, err := zstr.ParseUint64(num)
+	if err == nil && bits < 64 && v >= 1<<bits {
+		err = strconv.ErrRange
+	}
+	if err != nil {
+		it.failNumber(num, typ, err)
+		return 0
+	}
+	return v
+}
+
+func (it *Iterator) ReadFloat32() float32 {
+	return float32(it.readFloat(32, "float32"))
+}
+
+func (it *Iterator) ReadFloat64() float64 {
+	return it.readFloat(64, "float64")
+}
+
+func (it *Iterator) readFloat(bits int, typ string) float64 {
+	num := it.ReadNumber()
+	if num == nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(zgo.B2S(num), bits)
+	if err != nil {
+		it.failNumber(num, typ, err)
+		return 0
+	}
+	return v
+}
+
+// ReadBytes reads base64 string or array of bytes into new slice, as []byte is encoded
+func (it *Iterator) ReadBytes() []byte {
+	if it.peek() == '[' {
+		data := []byte{}
+		it.ReadArray(func() bool {
+			data = append(data, it.ReadUint8())
+			return true
+		})
+		return data
+	}
+	s := it.ReadStringBytes()
+	if it.err != nil {
+		return nil
+	}
+	data := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+	n, err := base64.StdEncoding.Decode(data, s)
+	if err != nil {
+		it.err = fmt.Errorf("json: cannot read base64 string at offset %d: %w", it.InputOffset(), err)
+		return nil
+	}
+	return data[:n]
+}
+
+// ReadQuoted reads string with encoded value, as fields with string tag option are,
+// read is called to read the value from iterator of the string content
+func (it *Iterator) ReadQuoted(read func(q *Iterator)) {
+	s := it.ReadStringBytes()
+	if it.err != nil {
+		return
+	}
+	q := Iterator{buf: s, mark: -1}
+	read(&q)
+	if err := q.End(); err != nil {
+		it.err = fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q: %w", s, err)
+	}
+}
+
+// ReadValue reads the next value into v by Unmarshal
+func (it *Iterator) ReadValue(v any) {
+	raw := it.ReadRaw()
+	if it.err != nil {
+		return
+	}
+	if err := Unmarshal(raw, v); err != nil {
+		it.err = err
+	}
+}
+
+// ReadField reads the next value into v as struct field with jessy json tag options,
+// e.g. "bytes=hex", by Unmarshal
+func (it *Iterator) ReadField(v any, options string) {
+	raw := it.ReadRaw()
+	if it.err != nil {
+		return
+	}
+	if err := std.UnmarshalOptions(raw, v, options); err != nil {
+		it.err = err
+	}
+}
+
+// End returns the first error of reading or syntax error if input has more values
+func (it *Iterator) End() error {
+	if c := it.peek(); c != 0 && it.err == nil {
+		it.failAt(c, "after top-level value")
+	}
+	return it.err
+}
+
+// ReadArray calls f for each array element, f reads the element or it is skipped,
+// false result of f skips the rest of array. Null is read as empty array
+func (it *Iterator) ReadArray(f func() bool) {
+	switch c := it.peek(); c {
+	case 'n':
+		it.ReadNull()
+		return
+	case '[':
+	default:
+		it.failAt(c, "looking for beginning of array")
+		return
+	}
+	it.pos++
+	if it.peek() == ']' {
+		it.pos++
+		it.reads++
+		return
+	}
+	stop := false
+	for {
+		if stop {
+			it.skip(0)
+		} else {
+			reads := it.reads
+			stop = !f()
+			if it.reads == reads {
+				it.skip(0)
+			}
+		}
+		switch c := it.peek(); c {
+		case ',':
+			it.pos++
+		case ']':
+			it.pos++
+			it.reads++
+			return
+		default:
+			it.failAt(c, "after array element")
+			return
+		}
+	}
+}
+
+// ReadObject calls f for each object key, f reads the value or it is skipped,
+// false result of f skips the rest of object. The key is valid until the value is read.
+// Null is read as empty object
+func (it *Iterator) ReadObject(f func(key []byte) bool) {
+	switch c := it.peek(); c {
+	case 'n':
+		it.ReadNull()
+		return
+	case '{':
+	default:
+		it.failAt(c, "looking for beginning of object")
+		return
+	}
+	it.pos++
+	if it.peek() == '}' {
+		it.pos++
+		it.reads++
+		return
+	}
+	stop := false
+	for {
+		if c := it.peek(); c != '"' {
+			it.failAt(c, "looking for beginning of object key string")
+			return
+		}
+		var key []byte
+		if stop {
+			it.scanString(nil)
+		} else if key = it.scanString(&it.key); it.r != nil {
+			// key in reader buffer is moved by reading of value
+			it.key = append(it.key[:0], key...)
+			key = it.key
+		}
+		if c := it.peek(); c != ':' {
+			it.failAt(c, "after object key")
+			return
+		}
+		it.pos++
+		if stop {
+			it.skip(0)
+		} else {
+			reads := it.reads
+			stop = !f(key)
+			if it.reads == reads {
+				it.skip(0)
+			}
+		}
+		switch c := it.peek(); c {
+		case ',':
+			it.pos++
+		case '}':
+			it.pos++
+			it.reads++
+			return
+		default:
+			it.failAt(c, "after object key:value pair")
+			return
+		}
+	}
+}
+
+// Skip reads the next value without decoding
+func (it *Iterator) Skip() {
+	it.skip(0)
+	if it.err == nil {
+		it.reads++
+	}
+}
+
+// ReadRaw reads the next value as is
+func (it *Iterator) ReadRaw() []byte {
+	if it.peek() == 0 {
+		it.failAt(0, "looking for beginning of value")
+		return nil
+	}
+	it.mark = it.pos
+	it.skip(0)
+	raw := it.buf[it.mark:it.pos]
+	it.mark = -1
+	if it.err != nil {
+		return nil
+	}
+	it.reads++
+	return raw
+}
+
+func (it *Iterator) skip(depth int) {
+	c := it.peek()
+	switch c {
+	case '"':
+		it.scanString(nil)
+		return
+	case 'n':
+		it.expect("null")
+		return
+	case 't':
+		it.expect("true")
+		return
+	case 'f':
+		it.expect("false")
+		return
+	case '{', '[':
+		if depth == iteratorMaxDepth {
+			it.fail("exceeded max depth")
+			return
+		}
+	default:
+		if c == '-' || ('0' <= c && c <= '9') {
+			it.scanNumber()
+		} else {
+			it.failAt(c, "looking for beginning of value")
+		}
+		return
+	}
+
+	it.pos++
+	if c == '[' {
+		if it.peek() == ']' {
+			it.pos++
+			return
+		}
+		for {
+			if it.skip(depth + 1); it.err != nil {
+				return
+			}
+			switch c = it.peek(); c {
+			case ',':
+				it.pos++
+			case ']':
+				it.pos++
+				return
+			default:
+				it.failAt(c, "after array element")
+				return
+			}
+		}
+	}
+
+	if it.peek() == '}' {
+		it.pos++
+		return
+	}
+	for {
+		if c = it.peek(); c != '"' {
+			it.failAt(c, "looking for beginning of object key string")
+			return
+		}
+		it.scanString(nil)
+		if c = it.peek(); c != ':' {
+			it.failAt(c, "after object key")
+			return
+		}
+		it.pos++
+		if it.skip(depth + 1); it.err != nil {
+			return
+		}
+		switch c = it.peek(); c {
+		case ',':
+			it.pos++
+		case '}':
+			it.pos++
+			return
+		default:
+			it.failAt(c, "after object key:value pair")
+			return
+		}
+	}
+}
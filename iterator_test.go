@@ -0,0 +1,244 @@
+package jessy
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+const iteratorTestData = ` {
+	"id": -42, "big": 18446744073709551615, "pi": 3.14e0,
+	"name": "caf\u00e9 \"au\" lait\n", "ok": true, "no": false, "none": null,
+	"tags": ["a", "b\/c", "\ud83d\ude00"],
+	"nested": {"skip": [1, {"x": [true, null]}, "]"], "keep": 7},
+	"empty": {}, "list": []
+} `
+
+type iteratorTestResult struct {
+	ID     int64
+	Big    uint64
+	Pi     float64
+	Name   string
+	OK, No bool
+	None   bool
+	Tags   []string
+	Keep   int
+	Raw    string
+	Keys   []string
+}
+
+func readIteratorTest(it *Iterator) (r iteratorTestResult) {
+	it.ReadObject(func(key []byte) bool {
+		r.Keys = append(r.Keys, string(key))
+		switch string(key) {
+		case "id":
+			r.ID = it.ReadInt64()
+		case "big":
+			r.Big = it.ReadUint64()
+		case "pi":
+			r.Pi = it.ReadFloat64()
+		case "name":
+			r.Name = it.ReadString()
+		case "ok":
+			r.OK = it.ReadBool()
+		case "no":
+			r.No = it.ReadBool()
+		case "none":
+			r.None = it.ReadNull()
+		case "tags":
+			it.ReadArray(func() bool {
+				r.Tags = append(r.Tags, it.ReadString())
+				return true
+			})
+		case "nested":
+			it.ReadObject(func(key []byte) bool {
+				if string(key) == "skip" {
+					r.Raw = string(it.ReadRaw())
+					return true
+				}
+				r.Keep = it.ReadInt()
+				return true
+			})
+		}
+		// "empty" and "list" are skipped
+		return true
+	})
+	return
+}
+
+func TestIterator(t *testing.T) {
+	expected := iteratorTestResult{
+		ID: -42, Big: 18446744073709551615, Pi: 3.14,
+		Name: "café \"au\" lait\n", OK: true, None: true,
+		Tags: []string{"a", "b/c", "😀"},
+		Keep: 7, Raw: `[1, {"x": [true, null]}, "]"]`,
+		Keys: []string{"id", "big", "pi", "name", "ok", "no", "none", "tags", "nested", "empty", "list"},
+	}
+
+	it := NewIterator([]byte(iteratorTestData))
+	require.Equal(t, KindObject, it.Next())
+	require.Equal(t, expected, readIteratorTest(it))
+	require.NoError(t, it.Error())
+	require.Equal(t, KindInvalid, it.Next())
+	require.Equal(t, int64(len(iteratorTestData)), it.InputOffset())
+
+	// reader with small buffer refilled in the middle of every token
+	it = NewIteratorReader(iotest.OneByteReader(strings.NewReader(iteratorTestData)), 1)
+	require.Equal(t, expected, readIteratorTest(it))
+	require.NoError(t, it.Error())
+
+	// stream of values and stop of iteration
+	it = NewIteratorReader(strings.NewReader(`{"a":1,"b":2} [1,2,3] "x"`), 0)
+	var keys []string
+	it.ReadObject(func(key []byte) bool {
+		keys = append(keys, string(key))
+		return false
+	})
+	require.Equal(t, []string{"a"}, keys)
+	sum := 0
+	it.ReadArray(func() bool {
+		sum += it.ReadInt()
+		return sum < 3
+	})
+	require.Equal(t, 3, sum)
+	require.Equal(t, KindString, it.Next())
+	require.Equal(t, "x", it.ReadString())
+	require.Equal(t, KindInvalid, it.Next())
+	require.NoError(t, it.Error())
+
+	var (
+		i8     int8
+		u8     uint8
+		f32    float32
+		b1, b2 []byte
+		q      string
+		qi     int
+		m      map[string]int
+		hex    [2]byte
+	)
+	it = NewIterator([]byte(`[-128, 255, 1.5, "AQI=", [3, 4], "\"q\"", "-7", {"a": 1}, "abcd"]`))
+	n := 0
+	it.ReadArray(func() bool {
+		switch n {
+		case 0:
+			i8 = it.ReadInt8()
+		case 1:
+			u8 = it.ReadUint8()
+		case 2:
+			f32 = it.ReadFloat32()
+		case 3:
+			b1 = it.ReadBytes()
+		case 4:
+			b2 = it.ReadBytes()
+		case 5:
+			it.ReadQuoted(func(q2 *Iterator) { q = q2.ReadString() })
+		case 6:
+			it.ReadQuoted(func(q2 *Iterator) { qi = q2.ReadInt() })
+		case 7:
+			it.ReadValue(&m)
+		case 8:
+			it.ReadField(&hex, "bytes=hex")
+		}
+		n++
+		return true
+	})
+	require.NoError(t, it.End())
+	require.Equal(t, int8(-128), i8)
+	require.Equal(t, uint8(255), u8)
+	require.Equal(t, float32(1.5), f32)
+	require.Equal(t, []byte{1, 2}, b1)
+	require.Equal(t, []byte{3, 4}, b2)
+	require.Equal(t, "q", q)
+	require.Equal(t, -7, qi)
+	require.Equal(t, map[string]int{"a": 1}, m)
+	require.Equal(t, [2]byte{0xab, 0xcd}, hex)
+
+	buf := []byte(`{"a":[1,"s",{"b":null}],"c":1.5}`)
+	allocs := testing.AllocsPerRun(100, func() {
+		it.Reset(buf)
+		it.ReadObject(func(key []byte) bool {
+			if key[0] == 'c' {
+				it.ReadFloat64()
+			}
+			return true
+		})
+	})
+	require.Equal(t, 0.0, allocs)
+}
+
+func TestIteratorErrors(t *testing.T) {
+	cases := []struct {
+		data   string
+		read   func(it *Iterator)
+		offset int64
+	}{
+		{`{"a" 1}`, (*Iterator).Skip, 5},
+		{`[1,]`, (*Iterator).Skip, 3},
+		{`[1 2]`, (*Iterator).Skip, 3},
+		{`"abc`, (*Iterator).Skip, 4},
+		{"\"a\tb\"", (*Iterator).Skip, 2},
+		{`tru`, (*Iterator).Skip, 3},
+		{`nul!`, (*Iterator).Skip, 3},
+		{`-`, (*Iterator).Skip, 1},
+		{`1.`, (*Iterator).Skip, 2},
+		{`1e+`, (*Iterator).Skip, 3},
+		{`"\x"`, func(it *Iterator) { it.ReadString() }, 1},
+		{`1`, func(it *Iterator) { it.ReadString() }, 0},
+		{`"1"`, func(it *Iterator) { it.ReadInt64() }, 0},
+		{`{"a":1`, func(it *Iterator) { it.ReadObject(func([]byte) bool { return true }) }, 6},
+		{`[1}`, func(it *Iterator) { it.ReadArray(func() bool { return true }) }, 2},
+		{strings.Repeat("[", iteratorMaxDepth+1), (*Iterator).Skip, iteratorMaxDepth},
+		{"\x00", (*Iterator).Skip, 0},
+		{"[1,\x00]", (*Iterator).Skip, 3},
+		{"{\"a\":1 \x00}", (*Iterator).Skip, 7},
+		{"1\x00", func(it *Iterator) { it.ReadInt(); it.End() }, 1},
+		{"[] \x00", func(it *Iterator) { it.Skip(); it.End() }, 3},
+	}
+	for _, c := range cases {
+		for _, it := range []*Iterator{
+			NewIterator([]byte(c.data)),
+			NewIteratorReader(iotest.OneByteReader(strings.NewReader(c.data)), 1),
+		} {
+			c.read(it)
+			var syntaxErr *SyntaxError
+			if !errors.As(it.Error(), &syntaxErr) {
+				t.Fatalf("%q: expected syntax error, actual %v", c.data, it.Error())
+			}
+			require.Equal(t, c.offset, syntaxErr.Offset)
+			require.Equal(t, KindInvalid, it.Next())
+		}
+	}
+
+	it := NewIterator([]byte(`[1.5, 99999999999999999999]`))
+	it.ReadArray(func() bool {
+		it.ReadInt64()
+		return true
+	})
+	require.NotEqual(t, nil, it.Error())
+
+	for _, data := range []string{`128`, `-129`} {
+		it = NewIterator([]byte(data))
+		it.ReadInt8()
+		require.NotEqual(t, nil, it.Error())
+	}
+	it = NewIterator([]byte(`256`))
+	it.ReadUint8()
+	require.NotEqual(t, nil, it.Error())
+	it = NewIterator([]byte(`"@"`))
+	it.ReadBytes()
+	require.NotEqual(t, nil, it.Error())
+	it = NewIterator([]byte(`"1 2"`))
+	it.ReadQuoted(func(q *Iterator) { q.ReadInt() })
+	require.NotEqual(t, nil, it.Error())
+	it = NewIterator([]byte(`1 2`))
+	it.ReadInt()
+	require.NotEqual(t, nil, it.End())
+
+	it = NewIteratorReader(iotest.ErrReader(io.ErrUnexpectedEOF), 0)
+	it.Skip()
+	require.Equal(t, io.ErrUnexpectedEOF, it.Error())
+}
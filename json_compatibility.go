@@ -3,6 +3,7 @@ package jessy
 import (
 	"bytes"
 
+	"github.com/avpetkun/jessy-go/std"
 	"github.com/avpetkun/jessy-go/zstr"
 )
 
@@ -27,20 +28,53 @@ func HTMLEscape(dst *bytes.Buffer, src []byte) {
 // at the end of src are preserved and copied to dst.
 // For example, if src has no trailing spaces, neither will dst;
 // if src ends in a trailing newline, so will dst.
-func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) (err error) {
+//
+// If src is not valid JSON, Indent returns a [SyntaxError] and dst is unchanged.
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
 	dst.Grow(len(src) * 2)
-	b := dst.AvailableBuffer()
-	b = zstr.AppendIndent(b, src, prefix, indent)
-	_, err = dst.Write(b)
-	return
+	b, err := std.AppendIndent(dst.AvailableBuffer(), src, prefix, indent)
+	dst.Write(b)
+	return err
 }
 
 // Compact appends to dst the JSON-encoded src with
 // insignificant space characters elided.
-func Compact(dst *bytes.Buffer, src []byte) (err error) {
-	dst.Grow(len(src) * 2)
-	b := dst.AvailableBuffer()
-	b = zstr.AppendCompactJSON(b, src, false)
-	_, err = dst.Write(b)
-	return
+//
+// If src is not valid JSON, Compact returns a [SyntaxError] and dst is unchanged.
+func Compact(dst *bytes.Buffer, src []byte) error {
+	dst.Grow(len(src))
+	b, err := std.AppendCompact(dst.AvailableBuffer(), src, false)
+	dst.Write(b)
+	return err
+}
+
+// CompactFlags is Compact which also escapes string literals
+// by EscapeHTML, EscapeUnicode, EscapeSlash and KeepInvalidUTF8 flags
+func CompactFlags(dst *bytes.Buffer, src []byte, flags Flags) error {
+	return appendEscapedJSON(dst, src, flags, true)
+}
+
+// HTMLEscapeFlags is HTMLEscape which also escapes string literals
+// by EscapeUnicode, EscapeSlash and KeepInvalidUTF8 flags.
+//
+// If src is not valid JSON, HTMLEscapeFlags returns a [SyntaxError] and dst is unchanged.
+func HTMLEscapeFlags(dst *bytes.Buffer, src []byte, flags Flags) error {
+	return appendEscapedJSON(dst, src, flags|EscapeHTML, false)
+}
+
+// appendEscapedJSON validates src while escaping its strings in a single pass
+func appendEscapedJSON(dst *bytes.Buffer, src []byte, flags Flags, compact bool) error {
+	if compact && !flags.Has(escapeStringFlags) {
+		dst.Grow(len(src))
+		b, err := std.AppendCompact(dst.AvailableBuffer(), src, flags.Has(EscapeHTML))
+		dst.Write(b)
+		return err
+	}
+	escapeFlags := flags.escapeFlags()
+	dst.Grow(len(src) + len(src)/8)
+	b, err := std.AppendCompactStrings(dst.AvailableBuffer(), src, compact, func(dst, s []byte) []byte {
+		return zstr.AppendEscapedJSONString(dst, s, escapeFlags)
+	})
+	dst.Write(b)
+	return err
 }
@@ -0,0 +1,77 @@
+package jessy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+func TestCompactIndentValidate(t *testing.T) {
+	valid := []string{
+		`{"a": [1, 2, {}], "b": {"c": "x,y:z"}, "d": []}`,
+		` [ true , false , null , -1.5e3 , "\"" ] `,
+		`"<html>&"`,
+	}
+	for _, src := range valid {
+		var expected, actual bytes.Buffer
+		require.NoError(t, json.Compact(&expected, []byte(src)))
+		require.NoError(t, Compact(&actual, []byte(src)))
+		require.Equal(t, expected.String(), actual.String())
+
+		expected.Reset()
+		actual.Reset()
+		require.NoError(t, json.Indent(&expected, []byte(src), ">", "  "))
+		require.NoError(t, Indent(&actual, []byte(src), ">", "  "))
+		require.Equal(t, expected.String(), actual.String())
+	}
+
+	invalid := []struct {
+		src    string
+		offset int64
+	}{
+		{`{"a": 1,}`, 9},
+		{`[1, 2`, 5},
+		{`{"a" 1}`, 6},
+		{`tru`, 3},
+	}
+	for _, tc := range invalid {
+		for _, format := range []func(*bytes.Buffer, []byte) error{
+			Compact,
+			func(dst *bytes.Buffer, src []byte) error { return Indent(dst, src, "", "\t") },
+			func(dst *bytes.Buffer, src []byte) error { return CompactFlags(dst, src, EscapeUnicode) },
+			func(dst *bytes.Buffer, src []byte) error { return HTMLEscapeFlags(dst, src, 0) },
+		} {
+			buf := bytes.NewBufferString("prev")
+			err := format(buf, []byte(tc.src))
+			var syntaxErr *SyntaxError
+			require.Equal(t, true, errors.As(err, &syntaxErr))
+			require.Equal(t, tc.offset, syntaxErr.Offset)
+			require.Equal(t, "prev", buf.String())
+		}
+	}
+}
+
+func TestCompactFlagsSinglePass(t *testing.T) {
+	srcs := []string{
+		`{"a": [1, 2, {}], "b": {"c": "x,y:z"}, "d": []}`,
+		` [ "<\"é\">" , "a/b\\" , " �" , "` + "\xff" + `" , "" ] `,
+		"{\"k\\\"<\": \"\\u00e9/\xe2\x80\xa8\"}",
+	}
+	for _, src := range srcs {
+		for _, flags := range []Flags{EscapeHTML, EscapeUnicode, EscapeSlash, KeepInvalidUTF8, EscapeHTML | EscapeUnicode | EscapeSlash} {
+			var buf bytes.Buffer
+			require.NoError(t, CompactFlags(&buf, []byte(src), flags))
+			expected := zstr.AppendCompactJSONFlags(nil, []byte(src), flags.escapeFlags())
+			require.Equal(t, string(expected), buf.String())
+
+			buf.Reset()
+			require.NoError(t, HTMLEscapeFlags(&buf, []byte(src), flags))
+			expected = zstr.AppendEscapeJSONFlags(nil, []byte(src), (flags | EscapeHTML).escapeFlags())
+			require.Equal(t, string(expected), buf.String())
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding"
 	"encoding/json"
 	"reflect"
+
+	"github.com/avpetkun/jessy-go/std"
 )
 
 var (
@@ -27,6 +29,18 @@ type (
 	// be used to delay JSON decoding or precompute a JSON encoding.
 	RawMessage = json.RawMessage
 
+	// A SyntaxError is a description of a JSON syntax error
+	// with Offset of the error in the input.
+	SyntaxError = std.SyntaxError
+
+	// An UnmarshalTypeError describes a JSON value that was
+	// not appropriate for a value of a specific Go type.
+	UnmarshalTypeError = std.UnmarshalTypeError
+
+	// An UnsupportedTypeError is returned by Marshal when attempting
+	// to encode an unsupported value type.
+	UnsupportedTypeError = json.UnsupportedTypeError
+
 	// type TextMarshaler interface {
 	//	 MarshalText() (text []byte, err error)
 	// }
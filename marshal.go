@@ -117,6 +117,15 @@ func AppendIndentFast(dst []byte, value any, prefix, indent string) ([]byte, err
 }
 
 func AppendIndentFlags(dst []byte, value any, flags Flags, prefix, indent string) (data []byte, err error) {
+	if style, ok := implicitIndentStyleFlags(prefix, indent); ok {
+		data = append(dst, prefix...)
+		data, err = encodeAny(data, value, flags.withIndentStyle(style))
+		if err != nil {
+			return dst, err
+		}
+		return data, nil
+	}
+	// out of indent styles, indent compact output
 	buf := getMarshalBuf()
 	data = buf.AvailableBuffer()
 	data, err = encodeAny(data, value, flags)
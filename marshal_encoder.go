@@ -2,8 +2,10 @@ package jessy
 
 import (
 	"io"
+	"runtime"
 	"slices"
 
+	"github.com/avpetkun/jessy-go/zgo"
 	"github.com/avpetkun/jessy-go/zstr"
 )
 
@@ -22,6 +24,16 @@ type Encoder struct {
 
 	indentPrefix string
 	indentValue  string
+	indentStyle  Flags
+
+	flushThreshold int
+
+	// arrays and objects written incrementally
+	scopes     []encoderScope
+	scopeSeps  []*streamSeps
+	scopeFlags Flags
+	scopeErr   error // error of item which is partially written
+	stream     encodeStream
 
 	marshalBuf []byte
 	indentBuf  []byte
@@ -30,6 +42,21 @@ type Encoder struct {
 var encoderEndline = []byte{'\n'}
 
 func (e *Encoder) Encode(value any) (err error) {
+	if len(e.scopes) != 0 {
+		return errEncoderScopeOpen
+	}
+	if e.flushThreshold > 0 && !e.flags.Has(Colorize) && (e.indentStyle != 0 || len(e.indentPrefix)+len(e.indentValue) == 0) {
+		return e.encodeStream(value)
+	}
+	if e.indentStyle != 0 {
+		e.marshalBuf = append(e.marshalBuf[:0], e.indentPrefix...)
+		e.marshalBuf, err = encodeAny(e.marshalBuf, value, e.flags.withIndentStyle(e.indentStyle))
+		if err == nil {
+			e.marshalBuf = append(e.marshalBuf, '\n')
+			_, err = e.Write(e.marshalBuf)
+		}
+		return
+	}
 	e.marshalBuf, err = encodeAny(e.marshalBuf[:0], value, e.flags)
 	if err == nil {
 		if len(e.indentPrefix) == 0 && len(e.indentValue) == 0 {
@@ -46,7 +73,42 @@ func (e *Encoder) Encode(value any) (err error) {
 	return
 }
 
+func (e *Encoder) encodeStream(value any) (err error) {
+	flags := e.flags
+	s := encodeStream{w: e.Writer, threshold: e.flushThreshold}
+	if e.indentStyle != 0 {
+		flags = flags.withIndentStyle(e.indentStyle)
+		s.buf = append(e.marshalBuf[:0], e.indentPrefix...)
+	} else {
+		s.buf = e.marshalBuf[:0]
+	}
+	eface := zgo.UnpackEface(value)
+	if eface.Type == nil {
+		s.buf = append(s.buf, 'n', 'u', 'l', 'l')
+	} else {
+		err = getStreamEncoder(eface.Type, flags, 0)(&s, eface.Data)
+		runtime.KeepAlive(value)
+	}
+	if err == nil {
+		s.buf = append(s.buf, '\n')
+		_, err = s.w.Write(s.buf)
+	}
+	e.marshalBuf = s.buf[:0]
+	return
+}
+
 func (e *Encoder) EncodeRaw(value any) (data []byte, err error) {
+	if len(e.scopes) != 0 {
+		return nil, errEncoderScopeOpen
+	}
+	if e.indentStyle != 0 {
+		e.marshalBuf = append(e.marshalBuf[:0], e.indentPrefix...)
+		e.marshalBuf, err = encodeAny(e.marshalBuf, value, e.flags.withIndentStyle(e.indentStyle))
+		if err == nil {
+			data = e.marshalBuf
+		}
+		return
+	}
 	e.marshalBuf, err = encodeAny(e.marshalBuf[:0], value, e.flags)
 	if err == nil {
 		if len(e.indentPrefix) == 0 && len(e.indentValue) == 0 {
@@ -100,7 +162,20 @@ func (e *Encoder) SetPrettyFlags(on bool) {
 	}
 }
 
+// SetFlushThreshold makes Encode write output to the writer every time
+// it grows over size bytes between items of structs, slices, arrays and maps,
+// so memory is bounded for huge values. Zero size disables it.
+// On error a part of the value may be already written.
+func (e *Encoder) SetFlushThreshold(size int) {
+	e.flushThreshold = size
+}
+
 func (e *Encoder) SetIndent(prefix, indent string) {
 	e.indentPrefix = prefix
 	e.indentValue = indent
+	e.indentStyle = 0
+	if len(prefix) != 0 || len(indent) != 0 {
+		// zero style means indent compact output if styles are out
+		e.indentStyle, _ = implicitIndentStyleFlags(prefix, indent)
+	}
 }
@@ -0,0 +1,214 @@
+package jessy
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/avpetkun/jessy-go/zgo"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+var (
+	errEncoderNoScope   = errors.New("json: no array or object to end")
+	errEncoderNotArray  = errors.New("json: element is written outside of array")
+	errEncoderNotObject = errors.New("json: field is written outside of object")
+	errEncoderScopeOpen = errors.New("json: value is encoded before array or object is ended")
+)
+
+// encoderScopeThreshold is default size of output kept before writing
+// while array or object is written incrementally
+const encoderScopeThreshold = 4096
+
+// encoderScope is array or object opened by Begin methods of Encoder
+type encoderScope struct {
+	close   byte
+	items   int
+	start   int
+	flushes int
+}
+
+// BeginArray starts top level array or array element of the current array,
+// elements are written by WriteElement and the array is closed by End
+func (e *Encoder) BeginArray() error {
+	return e.beginScope('[', ']', "", false)
+}
+
+// BeginObject starts top level object or object element of the current array,
+// fields are written by WriteField and the object is closed by End
+func (e *Encoder) BeginObject() error {
+	return e.beginScope('{', '}', "", false)
+}
+
+// BeginArrayField starts array as key field of the current object
+func (e *Encoder) BeginArrayField(key string) error {
+	return e.beginScope('[', ']', key, true)
+}
+
+// BeginObjectField starts object as key field of the current object
+func (e *Encoder) BeginObjectField(key string) error {
+	return e.beginScope('{', '}', key, true)
+}
+
+// WriteElement encodes value as element of the current array
+func (e *Encoder) WriteElement(value any) error {
+	return e.writeItem("", false, value)
+}
+
+// WriteField encodes value as key field of the current object,
+// the field is skipped like struct field if value encoder writes nothing
+func (e *Encoder) WriteField(key string, value any) error {
+	return e.writeItem(key, true, value)
+}
+
+// End closes the current array or object, the output of closed
+// top level value is written to the writer with trailing newline.
+// If a part of failed item was already written, End returns its error
+// and the top level value is not finished
+func (e *Encoder) End() error {
+	last := len(e.scopes) - 1
+	if last < 0 {
+		return errEncoderNoScope
+	}
+	scope := e.scopes[last]
+	e.scopes = e.scopes[:last]
+
+	s := &e.stream
+	if err := e.scopeErr; err != nil {
+		if last == 0 {
+			e.scopeErr = nil
+			e.marshalBuf = s.buf[:0]
+			s.buf = nil
+		}
+		return err
+	}
+	e.getScopeSeps(last).appendClose(s, scope.close, scope.items, scope.start, scope.flushes)
+	if last == 0 {
+		s.buf = append(s.buf, '\n')
+		_, err := s.w.Write(s.buf)
+		e.marshalBuf = s.buf[:0]
+		s.buf = nil
+		return err
+	}
+	return e.countScopeItem(last - 1)
+}
+
+// Flush writes output of opened arrays and objects to the writer
+func (e *Encoder) Flush() error {
+	s := &e.stream
+	if len(s.buf) == 0 {
+		return nil
+	}
+	_, err := s.w.Write(s.buf)
+	s.buf = s.buf[:0]
+	s.flushes++
+	return err
+}
+
+func (e *Encoder) beginScope(open, close byte, key string, field bool) error {
+	if e.scopeErr != nil {
+		return e.scopeErr
+	}
+	if len(e.scopes) == 0 {
+		if field {
+			return errEncoderNotObject
+		}
+		e.beginTopScope()
+	} else if _, _, err := e.appendItemKey(key, field); err != nil {
+		return err
+	}
+	s := &e.stream
+	e.scopes = append(e.scopes, encoderScope{close: close, start: len(s.buf), flushes: s.flushes})
+	s.buf = append(s.buf, open)
+	return nil
+}
+
+func (e *Encoder) beginTopScope() {
+	flags := e.flags.Exclude(Colorize)
+	if e.indentStyle != 0 {
+		flags = flags.withIndentStyle(e.indentStyle)
+	}
+	if flags != e.scopeFlags {
+		e.scopeFlags = flags
+		e.scopeSeps = e.scopeSeps[:0]
+	}
+	threshold := e.flushThreshold
+	if threshold <= 0 {
+		threshold = encoderScopeThreshold
+	}
+	e.stream = encodeStream{w: e.Writer, buf: e.marshalBuf[:0], threshold: threshold}
+	if e.indentStyle != 0 {
+		e.stream.buf = append(e.stream.buf, e.indentPrefix...)
+	}
+}
+
+func (e *Encoder) getScopeSeps(depth int) *streamSeps {
+	for len(e.scopeSeps) <= depth {
+		e.scopeSeps = append(e.scopeSeps, newStreamSeps(uint32(len(e.scopeSeps)), e.scopeFlags))
+	}
+	return e.scopeSeps[depth]
+}
+
+// appendItemKey writes separator and key of the next item of the current scope,
+// it returns buffer state to trim the item if its value is empty
+func (e *Encoder) appendItemKey(key string, field bool) (itemIndex, itemFlushes int, err error) {
+	last := len(e.scopes) - 1
+	if last < 0 || field != (e.scopes[last].close == '}') {
+		if field {
+			return 0, 0, errEncoderNotObject
+		}
+		return 0, 0, errEncoderNotArray
+	}
+	s := &e.stream
+	itemIndex, itemFlushes = len(s.buf), s.flushes
+	seps := e.getScopeSeps(last)
+	s.buf = seps.appendItem(s.buf, e.scopes[last].items)
+	if field {
+		s.buf = zstr.AppendQuotedStringFlags(s.buf, zgo.S2B(key), e.scopeFlags.escapeFlags())
+		s.buf = append(s.buf, seps.colon...)
+	}
+	return
+}
+
+func (e *Encoder) writeItem(key string, field bool, value any) error {
+	if e.scopeErr != nil {
+		return e.scopeErr
+	}
+	itemIndex, itemFlushes, err := e.appendItemKey(key, field)
+	if err != nil {
+		return err
+	}
+	s := &e.stream
+	valIndex := len(s.buf)
+
+	eface := zgo.UnpackEface(value)
+	if eface.Type == nil {
+		s.buf = append(s.buf, 'n', 'u', 'l', 'l')
+	} else {
+		var indent uint32
+		if e.scopeFlags.Has(PrettySpaces) {
+			indent = uint32(len(e.scopes))
+		}
+		err = getStreamEncoder(eface.Type, e.scopeFlags, indent)(s, eface.Data)
+		runtime.KeepAlive(value)
+		if err != nil {
+			if s.flushes == itemFlushes {
+				s.buf = s.buf[:itemIndex]
+			} else {
+				// a part of the item is already written
+				e.scopeErr = err
+			}
+			return err
+		}
+	}
+	if s.flushes == itemFlushes && len(s.buf) == valIndex {
+		s.buf = s.buf[:itemIndex]
+		return nil
+	}
+	return e.countScopeItem(len(e.scopes) - 1)
+}
+
+func (e *Encoder) countScopeItem(depth int) error {
+	scope := &e.scopes[depth]
+	scope.items++
+	return e.getScopeSeps(depth).flush(&e.stream, scope.start, scope.flushes, scope.items)
+}
@@ -0,0 +1,126 @@
+package jessy
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestEncoderScopes(t *testing.T) {
+	type Row struct {
+		ID   int    `json:"id"`
+		Name string `json:"name,omitempty"`
+	}
+	rows := []Row{{1, "a"}, {2, ""}, {3, "c"}}
+	value := map[string]any{
+		"count": len(rows),
+		"empty": []any{},
+		"meta":  map[string]any{"tags": []string{"x", "y"}},
+		"rows":  rows,
+	}
+
+	write := func(enc *Encoder) {
+		require.NoError(t, enc.BeginObject())
+		require.NoError(t, enc.WriteField("count", len(rows)))
+		require.NoError(t, enc.BeginArrayField("empty"))
+		require.NoError(t, enc.End())
+		require.NoError(t, enc.BeginObjectField("meta"))
+		require.NoError(t, enc.WriteField("tags", []string{"x", "y"}))
+		require.NoError(t, enc.End())
+		require.NoError(t, enc.BeginArrayField("rows"))
+		for _, row := range rows {
+			require.NoError(t, enc.WriteElement(row))
+		}
+		require.NoError(t, enc.End())
+		require.NoError(t, enc.End())
+	}
+
+	styles := []func(e *Encoder){
+		func(e *Encoder) {},
+		func(e *Encoder) { e.SetPrettyFlags(true) },
+		func(e *Encoder) { e.SetIndent("> ", "  ") },
+		func(e *Encoder) { e.SetFlags(EncodeStandard | IndentStyleWidth("", "  ", 30)) },
+		func(e *Encoder) { e.SetFlushThreshold(8) },
+	}
+	for _, style := range styles {
+		var expected, got bytes.Buffer
+		enc := NewEncoder(&expected)
+		style(enc)
+		require.NoError(t, enc.Encode(value))
+
+		enc = NewEncoder(&got)
+		style(enc)
+		write(enc)
+		require.Equal(t, expected.String(), got.String())
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	require.NoError(t, enc.BeginArray())
+	require.NoError(t, enc.WriteElement(1))
+	require.NoError(t, enc.Flush())
+	require.Equal(t, "[1", buf.String())
+	require.NoError(t, enc.WriteElement(nil))
+	require.NoError(t, enc.BeginArray())
+	require.NoError(t, enc.End())
+	require.NoError(t, enc.End())
+	require.Equal(t, "[1,null,[]]\n", buf.String())
+
+	require.Equal(t, errEncoderNoScope, enc.End())
+	require.Equal(t, errEncoderNotArray, enc.WriteElement(1))
+	require.Equal(t, errEncoderNotObject, enc.BeginObjectField("a"))
+	require.NoError(t, enc.BeginObject())
+	require.Equal(t, errEncoderNotArray, enc.WriteElement(1))
+	require.Equal(t, errEncoderNotArray, enc.BeginArray())
+	require.NoError(t, enc.End())
+}
+
+func TestEncoderScopesErrors(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	require.NoError(t, enc.Encode("warm"))
+	require.NoError(t, enc.BeginArray())
+	require.NoError(t, enc.WriteElement(1))
+	require.Equal(t, errEncoderScopeOpen, enc.Encode(12345))
+	_, err := enc.EncodeRaw(12345)
+	require.Equal(t, errEncoderScopeOpen, err)
+	require.NoError(t, enc.WriteElement(2))
+	require.NoError(t, enc.End())
+	require.Equal(t, "\"warm\"\n[1,2]\n", buf.String())
+
+	// failed items are trimmed
+	buf.Reset()
+	require.NoError(t, enc.BeginArray())
+	require.NoError(t, enc.WriteElement(1))
+	require.NotEqual(t, nil, enc.WriteElement(math.NaN()))
+	require.NotEqual(t, nil, enc.WriteElement([]any{2, math.Inf(1)}))
+	require.NoError(t, enc.WriteElement(2))
+	require.NoError(t, enc.BeginObject())
+	require.NotEqual(t, nil, enc.WriteField("a", math.NaN()))
+	require.NoError(t, enc.WriteField("b", 3))
+	require.NoError(t, enc.End())
+	require.NoError(t, enc.End())
+	require.Equal(t, "[1,2,{\"b\":3}]\n", buf.String())
+
+	// partially written item fails the whole value
+	buf.Reset()
+	enc.SetFlushThreshold(1)
+	require.NoError(t, enc.BeginArray())
+	require.NoError(t, enc.BeginArray())
+	err = enc.WriteElement([]any{1, 2, math.NaN()})
+	require.NotEqual(t, nil, err)
+	require.Equal(t, "[[[1,2", buf.String())
+	require.Equal(t, err, enc.WriteElement(3))
+	require.Equal(t, err, enc.BeginObject())
+	require.Equal(t, err, enc.End())
+	require.Equal(t, err, enc.End())
+	require.Equal(t, errEncoderNoScope, enc.End())
+
+	buf.Reset()
+	require.NoError(t, enc.BeginArray())
+	require.NoError(t, enc.WriteElement(1))
+	require.NoError(t, enc.End())
+	require.Equal(t, "[1]\n", buf.String())
+}
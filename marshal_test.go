@@ -1,12 +1,17 @@
 package jessy
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"math/big"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"unsafe"
 
 	//_ "net/http/pprof"
 
@@ -626,6 +631,109 @@ func TestMarshalAll(t *testing.T) {
 	}
 }
 
+type indentedMarshaler struct{}
+
+func (indentedMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte("{\n  \"a\": [1, 2],\n  \"b\": {}\n}"), nil
+}
+
+func TestMarshalIndentStyles(t *testing.T) {
+	type Empty struct {
+		A int `json:",omitempty"`
+	}
+	v := struct {
+		More      MoreStruct
+		Any       any
+		Array     [2]map[string]any
+		Empty     Empty
+		EmptyList []int
+		Marshaler indentedMarshaler
+	}{
+		More: getTestMoreStruct(),
+		Any: map[string]any{
+			"nested": []any{1, map[string]any{"x": []int{}}},
+		},
+		Array:     [2]map[string]any{{"a": 1}, nil},
+		EmptyList: []int{},
+	}
+	compact, err := Marshal(v)
+	require.NoError(t, err)
+
+	for _, style := range [][2]string{{"", "\t"}, {"", "  "}, {"//", "    "}} {
+		var expected bytes.Buffer
+		expected.WriteString(style[0])
+		require.NoError(t, json.Indent(&expected, compact, style[0], style[1]))
+
+		data, err := MarshalIndent(v, style[0], style[1])
+		require.NoError(t, err)
+		require.Equal(t, expected.String(), string(data))
+
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetIndent(style[0], style[1])
+		require.NoError(t, enc.Encode(v))
+		require.Equal(t, expected.String()+"\n", buf.String())
+	}
+
+	data, err := MarshalPretty(v)
+	require.NoError(t, err)
+	expected, err := MarshalIndent(v, "", "\t")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(data))
+}
+
+func TestIndentStylesLimit(t *testing.T) {
+	// restore registry for other tests
+	saved := indentStyles.Load()
+	t.Cleanup(func() {
+		indentStylesMu.Lock()
+		defer indentStylesMu.Unlock()
+		for _, style := range (*indentStyles.Load())[len(*saved):] {
+			indentStylesIDs.Delete(style)
+		}
+		indentStyles.Store(saved)
+	})
+	styles := func() int { return len(*indentStyles.Load()) }
+
+	v := map[string][]int{"a": {1, 2}}
+	check := func(prefix, indent string) {
+		var expected bytes.Buffer
+		expected.WriteString(prefix)
+		require.NoError(t, json.Indent(&expected, []byte(`{"a":[1,2]}`), prefix, indent))
+		data, err := MarshalIndent(v, prefix, indent)
+		require.NoError(t, err)
+		require.Equal(t, expected.String(), string(data))
+	}
+
+	// long indents are applied to compact output without adding styles
+	n := styles()
+	check("", strings.Repeat(" ", maxImplicitIndentLen+1))
+	require.Equal(t, n, styles())
+
+	// implicit styles leave ids to IndentStyle
+	for i := 0; styles() < maxImplicitIndentStyles; i++ {
+		check(strconv.Itoa(i), " ")
+	}
+	check("", "   \t")
+	require.Equal(t, maxImplicitIndentStyles, styles())
+
+	for styles() < maxIndentStyles {
+		_, err := NewIndentStyle("", "\t", styles())
+		require.NoError(t, err)
+	}
+	_, err := NewIndentStyle("", "  ", 1000)
+	require.Equal(t, ErrTooManyIndentStyles, err)
+	func() {
+		defer func() { require.Equal(t, ErrTooManyIndentStyles, recover()) }()
+		IndentStyleWidth("", "  ", 1000)
+	}()
+	// registered styles are still found
+	style, err := NewIndentStyle("", "\t", maxIndentStyles-1)
+	require.NoError(t, err)
+	require.Equal(t, style, IndentStyleWidth("", "\t", maxIndentStyles-1))
+	check("", "    \t")
+}
+
 func TestMarshalLoop(t *testing.T) {
 	t.SkipNow()
 
@@ -729,3 +837,86 @@ func BenchmarkMarshal(b *testing.B) {
 		}
 	})
 }
+
+func TestMarshalIndentWidth(t *testing.T) {
+	type Point struct {
+		X, Y float64
+	}
+	v := struct {
+		Name   string
+		Coords [][]float64
+		Points []Point
+		Tags   map[string]int
+		Long   []string
+	}{
+		Name:   "route",
+		Coords: [][]float64{{1.5, 2.5}, {3, 4}},
+		Points: []Point{{1, 2}},
+		Tags:   map[string]int{"a": 1, "b": 2},
+		Long:   []string{"aaaaaaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbbbbbb", "cccccccccccccccccccc"},
+	}
+	const expected = `{
+  "Coords": [[1.5, 2.5], [3, 4]],
+  "Long": [
+    "aaaaaaaaaaaaaaaaaaaa",
+    "bbbbbbbbbbbbbbbbbbbb",
+    "cccccccccccccccccccc"
+  ],
+  "Name": "route",
+  "Points": [{"X": 1, "Y": 2}],
+  "Tags": {"a": 1, "b": 2}
+}`
+	data, err := MarshalFlags(v, EncodeStandard|IndentStyleWidth("", "  ", 40))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+
+	compact, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(zstr.AppendIndentWidth(nil, compact, "", "  ", 40)))
+
+	data, err = MarshalFlags(v, EncodeStandard|IndentStyleWidth("", "  ", 1000))
+	require.NoError(t, err)
+	require.Equal(t, string(compact), strings.NewReplacer(", ", ",", ": ", ":").Replace(string(data)))
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	type Unsupported struct {
+		Ch   chan int       `json:"ch"`
+		Fn   func()         `json:"fn,omitempty"`
+		Fns  []func()       `json:"fns"`
+		Name string         `json:"name"`
+		Ptr  unsafe.Pointer `json:"ptr"`
+	}
+	v := Unsupported{Name: "x", Fns: []func(){nil}}
+
+	// same errors as of encoding/json
+	_, stdErr := json.Marshal(v)
+	for _, flags := range []Flags{EncodeFastest, EncodeStandard, EncodeStandard | PrettySpaces} {
+		_, err := MarshalFlags(v, flags)
+		var typeErr *UnsupportedTypeError
+		require.Equal(t, true, errors.As(err, &typeErr))
+		require.Equal(t, stdErr.Error(), err.Error())
+	}
+	_, err := Marshal(func() {})
+	require.Equal(t, `json: unsupported type: func()`, err.Error())
+	require.NotEqual(t, nil, NewEncoder(io.Discard).Encode(v))
+
+	w := NewWriter(nil)
+	w.Value(make(chan int))
+	_, err = w.Result()
+	require.NotEqual(t, nil, err)
+
+	_, err = MustCompilePath(`$.name`).SelectValue(v)
+	require.NotEqual(t, nil, err)
+
+	// fields are dropped with their keys
+	data, err := MarshalFlags(v, EncodeStandard|SkipUnsupported)
+	require.NoError(t, err)
+	require.Equal(t, `{"fns":[],"name":"x"}`, string(data))
+	data, err = MarshalFlags(v, EncodeStandard|SkipUnsupported|PrettySpaces)
+	require.NoError(t, err)
+	require.Equal(t, "{\n\t\"fns\": [],\n\t\"name\": \"x\"\n}", string(data))
+	var buf bytes.Buffer
+	require.NoError(t, NewEncoderWithFlags(&buf, EncodeStandard|SkipUnsupported).Encode(v))
+	require.Equal(t, "{\"fns\":[],\"name\":\"x\"}\n", buf.String())
+}
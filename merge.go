@@ -0,0 +1,298 @@
+package jessy
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"unsafe"
+
+	"github.com/avpetkun/jessy-go/std"
+	"github.com/avpetkun/jessy-go/zgo"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+// MergePatch applies JSON Merge Patch (RFC 7396) to target: members of patch object
+// replace members of target object recursively and null members remove them,
+// other patch values replace target. Unchanged values of target are copied as is
+func MergePatch(target, patch []byte) ([]byte, error) {
+	var it Iterator
+	it.Reset(target)
+	target = it.ReadRaw()
+	if err := it.End(); err != nil {
+		return nil, err
+	}
+	it.Reset(patch)
+	patch = it.ReadRaw()
+	if err := it.End(); err != nil {
+		return nil, err
+	}
+	return appendMergePatch(nil, target, patch), nil
+}
+
+// appendMergePatch appends target merged with patch, target is nil if it is missing
+func appendMergePatch(dst, target, patch []byte) []byte {
+	if kindOfByte[patch[0]] != KindObject {
+		return append(dst, patch...)
+	}
+	var keys []string
+	var members map[string][]byte
+	if target != nil && kindOfByte[target[0]] == KindObject {
+		keys, members = rawObjectMembers(target)
+	}
+	patchKeys, patchMembers := rawObjectMembers(patch)
+
+	dst = append(dst, '{')
+	start := len(dst)
+	appendKey := func(key string) {
+		if len(dst) != start {
+			dst = append(dst, ',')
+		}
+		dst = zstr.AppendQuotedString(dst, zgo.S2B(key), false)
+		dst = append(dst, ':')
+	}
+	for _, key := range keys {
+		value, ok := patchMembers[key]
+		if !ok {
+			appendKey(key)
+			dst = append(dst, members[key]...)
+		} else if value[0] != 'n' {
+			appendKey(key)
+			dst = appendMergePatch(dst, members[key], value)
+		}
+	}
+	for _, key := range patchKeys {
+		if value := patchMembers[key]; members[key] == nil && value[0] != 'n' {
+			appendKey(key)
+			dst = appendMergePatch(dst, nil, value)
+		}
+	}
+	return append(dst, '}')
+}
+
+// MergePatchInto applies JSON Merge Patch (RFC 7396) to value pointed by v without
+// decoding of it into maps: members of patch objects are set into struct fields
+// found by json keys as Unmarshal finds them and into maps with string keys,
+// null members set fields to zero values and remove keys of maps.
+// Other values are decoded by Unmarshal after merge with their JSON.
+// Members are applied one by one, so on error members applied before the failing one
+// stay applied, while the value of the failing member is not changed
+func MergePatchInto(v any, patch []byte) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &std.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	var it Iterator
+	it.Reset(patch)
+	patch = it.ReadRaw()
+	if err := it.End(); err != nil {
+		return err
+	}
+	return mergeValue(rv.Type().Elem(), rv.UnsafePointer(), EncodeStandard, patch)
+}
+
+// mergeValue merges patch into value of type t at ptr encoded with flags
+func mergeValue(t reflect.Type, ptr unsafe.Pointer, flags Flags, patch []byte) error {
+	if kindOfByte[patch[0]] == KindObject && !tMergeLeaf(t) {
+		switch t.Kind() {
+		case reflect.Pointer:
+			ref := reflect.NewAt(t, ptr).Elem()
+			if !ref.IsNil() {
+				return mergeValue(t.Elem(), ref.UnsafePointer(), flags.Exclude(OmitEmpty), patch)
+			}
+			elem := reflect.New(t.Elem())
+			if err := mergeValue(t.Elem(), elem.UnsafePointer(), flags.Exclude(OmitEmpty), patch); err != nil {
+				return err
+			}
+			ref.Set(elem)
+			return nil
+		case reflect.Struct:
+			return mergeStruct(t, ptr, flags, patch)
+		case reflect.Map:
+			if key := t.Key(); key.Kind() == reflect.String && !reflect.PointerTo(key).Implements(typeTextUnmarshaler) {
+				return mergeMap(t, ptr, flags, patch)
+			}
+		}
+	}
+
+	// other values are replaced by their JSON merged with patch
+	merged := patch
+	if kindOfByte[patch[0]] == KindObject {
+		current, err := encodeAt(nil, t, flags.Exclude(OmitEmpty), ptr)
+		if err != nil {
+			return err
+		}
+		if len(current) == 0 {
+			current = nil
+		}
+		merged = appendMergePatch(nil, current, patch)
+	}
+	if flags.Has(NeedQuotes) && merged[0] != 'n' && tQuotable(t) {
+		s, ok := pathString(merged)
+		if !ok {
+			return fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal unquoted value into %v", t)
+		}
+		merged = []byte(s)
+	}
+	// value is decoded aside to keep it unchanged on error
+	decoded := reflect.New(t)
+	if err := Unmarshal(merged, decoded.Interface()); err != nil {
+		return err
+	}
+	reflect.NewAt(t, ptr).Elem().Set(decoded.Elem())
+	return nil
+}
+
+// tQuotable reports types of values quoted by string option of field
+func tQuotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// tMergeLeaf reports types decoded by their own methods
+func tMergeLeaf(t reflect.Type) bool {
+	tp := reflect.PointerTo(t)
+	return tp.Implements(typeUnmarshaler) || tp.Implements(typeTextUnmarshaler)
+}
+
+func mergeStruct(t reflect.Type, ptr unsafe.Pointer, flags Flags, patch []byte) error {
+	fields := pathStructFields(t, flags, false)
+	var err error
+	var it Iterator
+	it.Reset(patch)
+	it.ReadObject(func(key []byte) bool {
+		value := it.ReadRaw()
+		chain := findStructField(fields, key, false)
+		if chain == nil {
+			if chain = findStructField(fields, key, true); chain == nil {
+				return true
+			}
+		}
+		// embedded structs of nil pointers are allocated on the way to the field
+		p := ptr
+		for _, f := range chain[:len(chain)-1] {
+			p = unsafe.Add(p, f.Offset)
+			if f.typ.Kind() == reflect.Pointer {
+				ref := reflect.NewAt(f.typ, p).Elem()
+				if ref.IsNil() {
+					ref.Set(reflect.New(f.typ.Elem()))
+				}
+				p = ref.UnsafePointer()
+			}
+		}
+		f := chain[len(chain)-1]
+		err = mergeValue(f.typ, unsafe.Add(p, f.Offset), f.flags, value)
+		return err == nil
+	})
+	return err
+}
+
+// findStructField returns field of key with embedded structs containing it
+// as Unmarshal finds it: of fields with the same name the shallowest one wins,
+// a tagged one if there are several at its depth, otherwise the name is ignored.
+// fold compares keys case-insensitively
+func findStructField(fields []StructField, key []byte, fold bool) []*StructField {
+	matches := collectStructFields(nil, nil, nil, fields, key, fold)
+	var found []*StructField
+	for i := range matches {
+		chain := dominantStructField(matches, matches[i].name)
+		if chain != nil && (found == nil || len(chain) < len(found)) {
+			found = chain
+		}
+	}
+	return found
+}
+
+// structFieldMatch is field of key with embedded structs containing it
+type structFieldMatch struct {
+	chain []*StructField
+	name  string
+}
+
+// collectStructFields appends fields of key flattening embedded structs,
+// visiting are types of embedded structs containing fields
+func collectStructFields(matches []structFieldMatch, parent []*StructField, visiting []reflect.Type, fields []StructField, key []byte, fold bool) []structFieldMatch {
+	for i := range fields {
+		f := &fields[i]
+		if f.KeyLen != 0 {
+			if name := f.name(); string(key) == name || (fold && bytes.EqualFold(key, zgo.S2B(name))) {
+				matches = append(matches, structFieldMatch{append(slices.Clip(parent), f), name})
+			}
+			continue
+		}
+		t := f.typ
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if slices.Contains(visiting, t) {
+			continue
+		}
+		matches = collectStructFields(matches, append(slices.Clip(parent), f), append(slices.Clip(visiting), t),
+			pathStructFields(t, f.flags, true), key, fold)
+	}
+	return matches
+}
+
+// dominantStructField returns field of name hiding others, nil if fields are ambiguous
+func dominantStructField(matches []structFieldMatch, name string) []*StructField {
+	var dominant []*StructField
+	ambiguous := false
+	for _, m := range matches {
+		if m.name != name {
+			continue
+		}
+		switch {
+		case dominant == nil || len(m.chain) < len(dominant):
+			dominant, ambiguous = m.chain, false
+		case len(m.chain) == len(dominant):
+			tagged := m.chain[len(m.chain)-1].tagged
+			if tagged == dominant[len(dominant)-1].tagged {
+				ambiguous = true
+			} else if tagged {
+				dominant, ambiguous = m.chain, false
+			}
+		}
+	}
+	if ambiguous {
+		return nil
+	}
+	return dominant
+}
+
+func mergeMap(t reflect.Type, ptr unsafe.Pointer, flags Flags, patch []byte) error {
+	m := reflect.NewAt(t, ptr).Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(t))
+	}
+	keyType, elemType := t.Key(), t.Elem()
+	var err error
+	var it Iterator
+	it.Reset(patch)
+	it.ReadObject(func(key []byte) bool {
+		k := reflect.ValueOf(string(key)).Convert(keyType)
+		value := it.ReadRaw()
+		if value[0] == 'n' {
+			m.SetMapIndex(k, reflect.Value{})
+			return true
+		}
+		elem := reflect.New(elemType)
+		if old := m.MapIndex(k); old.IsValid() {
+			elem.Elem().Set(old)
+		}
+		if err = mergeValue(elemType, elem.UnsafePointer(), flags.Exclude(OmitEmpty), value); err != nil {
+			return false
+		}
+		m.SetMapIndex(k, elem.Elem())
+		return true
+	})
+	return err
+}
@@ -0,0 +1,164 @@
+package jessy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a":{"b":"d"}}`},
+		{`{"a": [{"b":"c"}]}`, `{"a": [1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{` {"x": [1, 2], "y": {"z": 1}} `, `{"y": {"w": 2}}`, `{"x":[1, 2],"y":{"z":1,"w":2}}`},
+	}
+	for _, c := range cases {
+		result, err := MergePatch([]byte(c.target), []byte(c.patch))
+		require.NoError(t, err)
+		require.Equal(t, c.result, string(result))
+	}
+	_, err := MergePatch([]byte(`{`), []byte(`{}`))
+	require.NotEqual(t, nil, err)
+	_, err = MergePatch([]byte(`{}`), []byte(`{} {}`))
+	require.NotEqual(t, nil, err)
+}
+
+type mergeBase struct {
+	ID      int    `json:"id"`
+	Comment string `json:"comment"`
+}
+
+type mergeUser struct {
+	*mergeBase
+	Name    string            `json:"name"`
+	Age     int               `json:"age,string"`
+	Tags    []string          `json:"tags"`
+	Address *mergeAddress     `json:"address"`
+	Labels  map[string]string `json:"labels"`
+	Extra   map[string]any    `json:"extra"`
+	Any     any               `json:"any"`
+	Scores  map[int]int       `json:"scores"`
+	Seen    time.Time         `json:"seen"`
+	Skip    string            `json:"-"`
+}
+
+type mergeAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street,omitempty"`
+}
+
+func TestMergePatchInto(t *testing.T) {
+	user := mergeUser{
+		Name:    "ann",
+		Age:     30,
+		Tags:    []string{"a", "b"},
+		Address: &mergeAddress{City: "x", Street: "y"},
+		Labels:  map[string]string{"a": "1", "b": "2"},
+		Any:     map[string]any{"k": 1.0, "l": true},
+		Scores:  map[int]int{1: 1},
+		Skip:    "skip",
+	}
+	err := MergePatchInto(&user, []byte(`{
+		"comment": "new",
+		"NAME": "bob",
+		"age": "31",
+		"tags": ["c"],
+		"address": {"street": null},
+		"labels": {"a": null, "c": "3"},
+		"extra": {"x": {"y": 1, "z": null}},
+		"any": {"l": null, "m": [1]},
+		"scores": {"2": 2},
+		"seen": "2024-01-02T03:04:05Z",
+		"Skip": "changed",
+		"unknown": {"a": 1}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, &mergeBase{Comment: "new"}, user.mergeBase)
+	require.Equal(t, "bob", user.Name)
+	require.Equal(t, 31, user.Age)
+	require.Equal(t, []string{"c"}, user.Tags)
+	require.Equal(t, &mergeAddress{City: "x"}, user.Address)
+	require.Equal(t, map[string]string{"b": "2", "c": "3"}, user.Labels)
+	require.Equal(t, map[string]any{"x": map[string]any{"y": 1.0}}, user.Extra)
+	require.Equal(t, map[string]any{"k": 1.0, "m": []any{1.0}}, user.Any)
+	require.Equal(t, map[int]int{1: 1, 2: 2}, user.Scores)
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), user.Seen)
+	require.Equal(t, "skip", user.Skip)
+
+	// nil pointers are allocated, null sets zero values
+	var empty mergeUser
+	require.NoError(t, MergePatchInto(&empty, []byte(`{"address": {"city": "z"}, "name": null, "tags": null}`)))
+	require.Equal(t, &mergeAddress{City: "z"}, empty.Address)
+	require.NoError(t, MergePatchInto(&user, []byte(`{"address": null, "labels": null, "name": null}`)))
+	require.Equal(t, (*mergeAddress)(nil), user.Address)
+	require.Equal(t, map[string]string(nil), user.Labels)
+	require.Equal(t, "", user.Name)
+
+	m := map[string]int{"a": 1}
+	require.NoError(t, MergePatchInto(&m, []byte(`{"a": null, "b": 2}`)))
+	require.Equal(t, map[string]int{"b": 2}, m)
+
+	require.NotEqual(t, nil, MergePatchInto(user, []byte(`{}`)))
+	require.NotEqual(t, nil, MergePatchInto(&user, []byte(`{"age": 1}`)))
+	require.NotEqual(t, nil, MergePatchInto(&user, []byte(`{"tags": {"a": 1}}`)))
+	require.NotEqual(t, nil, MergePatchInto(&user, []byte(`{"name": "x"`)))
+
+	// failing values are not changed, members before them stay applied
+	user = mergeUser{Name: "ann", Tags: []string{"a"}}
+	require.NotEqual(t, nil, MergePatchInto(&user, []byte(`[1]`)))
+	require.Equal(t, mergeUser{Name: "ann", Tags: []string{"a"}}, user)
+	require.NotEqual(t, nil, MergePatchInto(&user, []byte(`{"name": "bob", "tags": "oops"}`)))
+	require.Equal(t, mergeUser{Name: "bob", Tags: []string{"a"}}, user)
+	require.NotEqual(t, nil, MergePatchInto(&user, []byte(`{"address": {"city": 1}}`)))
+	require.Equal(t, (*mergeAddress)(nil), user.Address)
+}
+
+type mergeInner struct {
+	Name string
+	City string `json:"town"`
+}
+
+type mergeOuter struct {
+	mergeInner
+	*BugD
+	Name string
+}
+
+func TestMergePatchIntoEmbedded(t *testing.T) {
+	// fields are found as Unmarshal finds them
+	check := func(target any, patch string) {
+		t.Helper()
+		expected := reflect.New(reflect.TypeOf(target).Elem())
+		require.NoError(t, Unmarshal([]byte(patch), expected.Interface()))
+		require.NoError(t, MergePatchInto(target, []byte(patch)))
+		require.Equal(t, expected.Elem().Interface(), reflect.ValueOf(target).Elem().Interface())
+	}
+	check(new(mergeOuter), `{"Name": "x", "town": "y", "S": "z"}`)
+	check(new(mergeOuter), `{"name": "x", "TOWN": "y", "s": "z"}`)
+	check(new(BugB), `{"S": "x"}`)
+	check(new(BugY), `{"S": "x"}`)
+	check(new(BugZ), `{"S": "x", "s": "y"}`)
+
+	v := mergeOuter{Name: "a"}
+	require.NoError(t, MergePatchInto(&v, []byte(`{"Name": "b"}`)))
+	require.Equal(t, mergeOuter{Name: "b"}, v)
+}
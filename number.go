@@ -0,0 +1,37 @@
+package jessy
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/avpetkun/jessy-go/std"
+	"github.com/avpetkun/jessy-go/zgo"
+)
+
+// NumberBigInt converts integer number literal to big.Int
+func NumberBigInt(n Number) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(string(n), 10)
+	if !ok {
+		return nil, fmt.Errorf("json: invalid big.Int number %q", string(n))
+	}
+	return v, nil
+}
+
+// NumberBigFloat converts number literal to big.Float
+// with precision enough to keep all its decimal digits
+func NumberBigFloat(n Number) (*big.Float, error) {
+	v := new(big.Float).SetPrec(std.BigFloatPrec(zgo.S2B(string(n))))
+	if _, _, err := v.Parse(string(n), 10); err != nil {
+		return nil, fmt.Errorf("json: invalid big.Float number %q: %w", string(n), err)
+	}
+	return v, nil
+}
+
+// NumberBigRat converts number literal to exact big.Rat
+func NumberBigRat(n Number) (*big.Rat, error) {
+	v, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return nil, fmt.Errorf("json: invalid big.Rat number %q", string(n))
+	}
+	return v, nil
+}
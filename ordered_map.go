@@ -0,0 +1,259 @@
+package jessy
+
+import (
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/avpetkun/jessy-go/std"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+func init() {
+	std.NewOrderedObject = func(keys []string, values []any) any {
+		m := NewOrderedMap[string, any](len(keys))
+		for i, key := range keys {
+			m.Set(key, values[i])
+		}
+		return m
+	}
+}
+
+// UnmarshalOrdered is Unmarshal storing JSON objects into interface values
+// as *OrderedMap[string, any] instead of map[string]any, so order of members
+// of documents is kept when they are encoded back
+func UnmarshalOrdered(data []byte, v any) error {
+	return std.UnmarshalOrdered(data, v)
+}
+
+// OrderedMap is a map keeping insertion order of keys. It is encoded as object
+// with members in that order regardless of SortMapKeys and decoded keeping
+// order of members. The zero OrderedMap is empty and ready to use
+type OrderedMap[K comparable, V any] struct {
+	entries []orderedEntry[K, V]
+	index   map[K]int
+}
+
+type orderedEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewOrderedMap returns empty map with space for capacity keys
+func NewOrderedMap[K comparable, V any](capacity int) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		entries: make([]orderedEntry[K, V], 0, capacity),
+		index:   make(map[K]int, capacity),
+	}
+}
+
+// Len returns count of keys
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Get returns value of key and reports whether it is present
+func (m *OrderedMap[K, V]) Get(key K) (value V, ok bool) {
+	if i, ok := m.index[key]; ok {
+		return m.entries[i].value, true
+	}
+	return value, false
+}
+
+// Set sets value of key, new keys are added to the end
+// and existing keys keep their position
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if i, ok := m.index[key]; ok {
+		m.entries[i].value = value
+		return
+	}
+	if m.index == nil {
+		m.index = make(map[K]int)
+	}
+	m.index[key] = len(m.entries)
+	m.entries = append(m.entries, orderedEntry[K, V]{key, value})
+}
+
+// Delete removes key shifting keys after it and reports whether it was present
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	i, ok := m.index[key]
+	if !ok {
+		return false
+	}
+	delete(m.index, key)
+	copy(m.entries[i:], m.entries[i+1:])
+	m.entries[len(m.entries)-1] = orderedEntry[K, V]{}
+	m.entries = m.entries[:len(m.entries)-1]
+	for ; i < len(m.entries); i++ {
+		m.index[m.entries[i].key] = i
+	}
+	return true
+}
+
+// Keys returns keys in order
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, len(m.entries))
+	for i := range m.entries {
+		keys[i] = m.entries[i].key
+	}
+	return keys
+}
+
+// Range calls f for keys and values in order until f returns false
+func (m *OrderedMap[K, V]) Range(f func(key K, value V) bool) {
+	for i := range m.entries {
+		if !f(m.entries[i].key, m.entries[i].value) {
+			return
+		}
+	}
+}
+
+// MarshalJSON returns JSON object of m by Marshal
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return Marshal(m)
+}
+
+// UnmarshalJSON adds members of JSON object to m in order, keys are decoded
+// as keys of maps by Unmarshal and values by UnmarshalOrdered. Null clears m
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	var it Iterator
+	it.Reset(data)
+	if it.Next() == KindNull {
+		*m = OrderedMap[K, V]{}
+		return nil
+	}
+	keyType := reflect.TypeFor[K]()
+	var err error
+	it.ReadObject(func(key []byte) bool {
+		var k K
+		if err = decodeMapKey(keyType, key, unsafe.Pointer(&k)); err != nil {
+			return false
+		}
+		var v V
+		if err = UnmarshalOrdered(it.ReadRaw(), &v); err != nil {
+			return false
+		}
+		m.Set(k, v)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return it.End()
+}
+
+// decodeMapKey decodes key of object into map key of type t at ptr as Unmarshal does
+func decodeMapKey(t reflect.Type, key []byte, ptr unsafe.Pointer) error {
+	v := reflect.NewAt(t, ptr)
+	if u, ok := v.Interface().(TextUnmarshaler); ok {
+		return u.UnmarshalText(key)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		v.Elem().SetString(string(key))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(string(key), 10, t.Bits())
+		if err == nil {
+			v.Elem().SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(string(key), 10, t.Bits())
+		if err == nil {
+			v.Elem().SetUint(n)
+			return nil
+		}
+	}
+	return &std.UnmarshalTypeError{Value: "number " + string(key), Type: t}
+}
+
+// orderedMapEncoding is implemented by OrderedMap types only
+type orderedMapEncoding interface {
+	orderedMapEncoder(deep, indent uint32, flags Flags) UnsafeEncoder
+}
+
+var typeOrderedMapEncoding = reflect.TypeFor[orderedMapEncoding]()
+
+// orderedMapEncoder returns encoder of members of map in order
+// with encoders of keys and values as map encoders create them
+func (OrderedMap[K, V]) orderedMapEncoder(deep, indent uint32, flags Flags) UnsafeEncoder {
+	encodeKey := createItemTypeEncoder(deep, indent+1, (flags | NeedQuotes), reflect.TypeFor[K]())
+	encodeVal := createItemTypeEncoder(deep, indent+1, flags, reflect.TypeFor[V]())
+	omitEmpty := flags.Has(OmitEmpty)
+	pretty := flags.Has(PrettySpaces)
+
+	deepSpaces0 := getIndent(flags, indent)
+	deepSpaces1 := getIndent(flags, indent+1)
+	width := getIndentStyle(flags).width
+
+	return func(dst []byte, value unsafe.Pointer) ([]byte, error) {
+		m := (*OrderedMap[K, V])(value)
+		if len(m.entries) == 0 {
+			if omitEmpty {
+				return dst, nil
+			}
+			return append(dst, '{', '}'), nil
+		}
+
+		start := len(dst)
+		dst = append(dst, '{')
+		if pretty {
+			dst = append(dst, '\n')
+		}
+		dstInitLen := len(dst)
+
+		var err error
+		for i := range m.entries {
+			e := &m.entries[i]
+			keyIndex := len(dst)
+			if pretty {
+				dst = append(dst, deepSpaces1...)
+			}
+			keyStart := len(dst)
+			dst, err = encodeKey(dst, unsafe.Pointer(&e.key))
+			if err != nil {
+				return dst, err
+			}
+			if len(dst) == keyStart {
+				dst = dst[:keyIndex]
+				continue
+			}
+			if pretty {
+				dst = append(dst, ':', ' ')
+			} else {
+				dst = append(dst, ':')
+			}
+			valIndex := len(dst)
+			dst, err = encodeVal(dst, unsafe.Pointer(&e.value))
+			if err != nil {
+				return dst, err
+			}
+			if len(dst) == valIndex {
+				dst = dst[:keyIndex]
+				continue
+			}
+			if pretty {
+				dst = append(dst, ',', '\n')
+			} else {
+				dst = append(dst, ',')
+			}
+		}
+
+		switch {
+		case len(dst) == dstInitLen:
+			dst = append(dst[:start], '{', '}')
+		case pretty:
+			dst = dst[:len(dst)-2]
+			dst = append(dst, '\n')
+			dst = append(dst, deepSpaces0...)
+			dst = append(dst, '}')
+			if width != 0 {
+				dst = zstr.CollapseIndent(dst, start, width, len(deepSpaces1), len(deepSpaces0))
+			}
+		default:
+			dst[len(dst)-1] = '}'
+		}
+		return dst, nil
+	}
+}
@@ -0,0 +1,90 @@
+package jessy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap[string, int]
+	m.Set("z", 1)
+	m.Set("a", 2)
+	m.Set("m", 3)
+	m.Set("z", 4)
+	require.Equal(t, 3, m.Len())
+	require.Equal(t, []string{"z", "a", "m"}, m.Keys())
+	v, ok := m.Get("z")
+	require.Equal(t, true, ok)
+	require.Equal(t, 4, v)
+
+	require.Equal(t, true, m.Delete("a"))
+	require.Equal(t, false, m.Delete("a"))
+	m.Set("a", 5)
+	require.Equal(t, []string{"z", "m", "a"}, m.Keys())
+	v, _ = m.Get("m")
+	require.Equal(t, 3, v)
+
+	var keys []string
+	m.Range(func(key string, value int) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	require.Equal(t, []string{"z", "m"}, keys)
+}
+
+type orderedDoc struct {
+	Name   string                      `json:"name"`
+	Fields OrderedMap[string, any]     `json:"fields"`
+	Codes  *OrderedMap[int, string]    `json:"codes,omitempty"`
+	Empty  OrderedMap[string, float64] `json:"empty,omitempty"`
+}
+
+func TestOrderedMapMarshal(t *testing.T) {
+	data := []byte(`{"name":"x","fields":{"z":1,"a":{"y":true,"b":[{"d":1,"c":2}]},"m":null},"codes":{"30":"c","10":"a"}}`)
+	var doc orderedDoc
+	require.NoError(t, Unmarshal(data, &doc))
+	require.Equal(t, []string{"z", "a", "m"}, doc.Fields.Keys())
+	require.Equal(t, []int{30, 10}, doc.Codes.Keys())
+	nested, _ := doc.Fields.Get("a")
+	require.Equal(t, []string{"y", "b"}, nested.(*OrderedMap[string, any]).Keys())
+
+	out, err := Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, `{"codes":{"30":"c","10":"a"},"fields":{"z":1,"a":{"y":true,"b":[{"d":1,"c":2}]},"m":null},"name":"x"}`, string(out))
+
+	out, err = MarshalIndent(doc.Codes, "", "  ")
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"30\": \"c\",\n  \"10\": \"a\"\n}", string(out))
+
+	// encoding/json uses MarshalJSON and UnmarshalJSON
+	out, err = json.Marshal(&doc.Fields)
+	require.NoError(t, err)
+	require.Equal(t, `{"z":1,"a":{"y":true,"b":[{"d":1,"c":2}]},"m":null}`, string(out))
+	var codes OrderedMap[int8, string]
+	require.NoError(t, json.Unmarshal([]byte(`{"3":"c","-1":"a"}`), &codes))
+	require.Equal(t, []int8{3, -1}, codes.Keys())
+
+	require.NotEqual(t, nil, Unmarshal([]byte(`{"codes":{"x":"a"}}`), &doc))
+	require.NotEqual(t, nil, Unmarshal([]byte(`{"codes":{"1000":1}}`), &doc))
+	require.NoError(t, Unmarshal([]byte(`{"codes":null}`), &doc))
+	require.Equal(t, (*OrderedMap[int, string])(nil), doc.Codes)
+}
+
+func TestUnmarshalOrdered(t *testing.T) {
+	data := []byte(`[{"z":1,"a":{"y":[{"c":1,"b":2}],"x":"s"}},{}]`)
+	var v any
+	require.NoError(t, UnmarshalOrdered(data, &v))
+	first := v.([]any)[0].(*OrderedMap[string, any])
+	require.Equal(t, []string{"z", "a"}, first.Keys())
+
+	out, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(out))
+
+	// plain Unmarshal keeps map[string]any
+	require.NoError(t, Unmarshal(data, &v))
+	_, ok := v.([]any)[0].(map[string]any)
+	require.Equal(t, true, ok)
+}
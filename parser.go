@@ -0,0 +1,181 @@
+package jessy
+
+import (
+	"io"
+)
+
+// Handler receives events of Parse, error returned by a handler stops parsing.
+// Keys, strings and numbers are valid only until the handler returns,
+// strings are passed with escape sequences decoded
+type Handler interface {
+	OnObjectStart() error
+	OnObjectEnd() error
+	OnArrayStart() error
+	OnArrayEnd() error
+	OnKey(key []byte) error
+	OnString(s []byte) error
+	OnNumber(num []byte) error
+	OnBool(v bool) error
+	OnNull() error
+}
+
+// NopHandler ignores all events, it is embedded into handlers of few events
+type NopHandler struct{}
+
+func (NopHandler) OnObjectStart() error  { return nil }
+func (NopHandler) OnObjectEnd() error    { return nil }
+func (NopHandler) OnArrayStart() error   { return nil }
+func (NopHandler) OnArrayEnd() error     { return nil }
+func (NopHandler) OnKey([]byte) error    { return nil }
+func (NopHandler) OnString([]byte) error { return nil }
+func (NopHandler) OnNumber([]byte) error { return nil }
+func (NopHandler) OnBool(bool) error     { return nil }
+func (NopHandler) OnNull() error         { return nil }
+
+// Parse calls h for every token of data in a single pass.
+// Data is one or more whitespace separated values
+func Parse(data []byte, h Handler) error {
+	var it Iterator
+	it.Reset(data)
+	return it.parse(h)
+}
+
+// ParseReader calls h for every token of r in a single pass.
+// Input is one or more whitespace separated values, it is read
+// by chunks and buffered only to hold the longest string or number
+func ParseReader(r io.Reader, h Handler) error {
+	var it Iterator
+	it.ResetReader(r)
+	return it.parse(h)
+}
+
+func (it *Iterator) parse(h Handler) (err error) {
+	var objects [iteratorMaxDepth/64 + 1]uint64 // bit is set for object levels
+	depth := 0
+
+	for {
+		// value is expected
+		switch c := it.peek(); c {
+		case '{', '[':
+			if depth == iteratorMaxDepth {
+				it.fail("exceeded max depth")
+				return it.err
+			}
+			it.pos++
+			if c == '{' {
+				objects[depth/64] |= 1 << (depth % 64)
+				err = h.OnObjectStart()
+			} else {
+				objects[depth/64] &^= 1 << (depth % 64)
+				err = h.OnArrayStart()
+			}
+			depth++
+			if err != nil {
+				return err
+			}
+			if c == '{' {
+				if c = it.peek(); c != '}' {
+					if err = it.parseKey(h); err != nil {
+						return err
+					}
+					continue
+				}
+			} else if c = it.peek(); c != ']' {
+				continue
+			}
+			// empty container is closed below
+		case '"':
+			if s := it.scanString(&it.str); it.err == nil {
+				err = h.OnString(s)
+			}
+		case 't':
+			if it.expect("true") {
+				err = h.OnBool(true)
+			}
+		case 'f':
+			if it.expect("false") {
+				err = h.OnBool(false)
+			}
+		case 'n':
+			if it.expect("null") {
+				err = h.OnNull()
+			}
+		default:
+			if c == '-' || ('0' <= c && c <= '9') {
+				if num := it.scanNumber(); it.err == nil {
+					err = h.OnNumber(num)
+				}
+			} else {
+				it.failAt(c, "looking for beginning of value")
+			}
+		}
+		if it.err != nil {
+			return it.err
+		}
+		if err != nil {
+			return err
+		}
+
+		// value is written, close containers or go to the next value
+		for {
+			if depth == 0 {
+				if it.peek() == 0 && it.err == nil {
+					return nil
+				}
+				break
+			}
+			object := objects[(depth-1)/64]&(1<<((depth-1)%64)) != 0
+			c := it.peek()
+			if c == ',' {
+				it.pos++
+				if object {
+					if err = it.parseKey(h); err != nil {
+						return err
+					}
+				}
+				break
+			}
+			switch {
+			case object && c == '}':
+				it.pos++
+				err = h.OnObjectEnd()
+			case !object && c == ']':
+				it.pos++
+				err = h.OnArrayEnd()
+			case object:
+				it.failAt(c, "after object key:value pair")
+				return it.err
+			default:
+				it.failAt(c, "after array element")
+				return it.err
+			}
+			if err != nil {
+				return err
+			}
+			depth--
+		}
+	}
+}
+
+// parseKey reads object key and colon
+func (it *Iterator) parseKey(h Handler) error {
+	if c := it.peek(); c != '"' {
+		it.failAt(c, "looking for beginning of object key string")
+		return it.err
+	}
+	key := it.scanString(&it.key)
+	if it.err != nil {
+		return it.err
+	}
+	if it.r != nil {
+		// key in reader buffer is moved by reading of colon
+		it.key = append(it.key[:0], key...)
+		key = it.key
+	}
+	if c := it.peek(); c != ':' {
+		it.failAt(c, "after object key")
+		return it.err
+	}
+	it.pos++
+	return h.OnKey(key)
+}
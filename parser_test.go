@@ -0,0 +1,88 @@
+package jessy
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/avpetkun/jessy-go/require"
+	"github.com/avpetkun/jessy-go/std"
+)
+
+type tokensHandler struct {
+	tokens []string
+	stopAt string
+}
+
+var errStopTokens = errors.New("stop")
+
+func (h *tokensHandler) add(token string) error {
+	h.tokens = append(h.tokens, token)
+	if token == h.stopAt {
+		return errStopTokens
+	}
+	return nil
+}
+
+func (h *tokensHandler) OnObjectStart() error      { return h.add("{") }
+func (h *tokensHandler) OnObjectEnd() error        { return h.add("}") }
+func (h *tokensHandler) OnArrayStart() error       { return h.add("[") }
+func (h *tokensHandler) OnArrayEnd() error         { return h.add("]") }
+func (h *tokensHandler) OnKey(key []byte) error    { return h.add("k:" + string(key)) }
+func (h *tokensHandler) OnString(s []byte) error   { return h.add("s:" + string(s)) }
+func (h *tokensHandler) OnNumber(num []byte) error { return h.add("n:" + string(num)) }
+func (h *tokensHandler) OnNull() error             { return h.add("null") }
+func (h *tokensHandler) OnBool(v bool) error {
+	if v {
+		return h.add("true")
+	}
+	return h.add("false")
+}
+
+func TestParse(t *testing.T) {
+	const data = ` {"a": [1, -2.5e3, "x\ty"], "b": {}, "c": [], "d": {"e": [true, false, null, [[]]]}} 7 "z" `
+	expected := []string{
+		"{", "k:a", "[", "n:1", "n:-2.5e3", "s:x\ty", "]", "k:b", "{", "}", "k:c", "[", "]",
+		"k:d", "{", "k:e", "[", "true", "false", "null", "[", "[", "]", "]", "]", "}", "}",
+		"n:7", "s:z",
+	}
+
+	h := new(tokensHandler)
+	require.NoError(t, Parse([]byte(data), h))
+	require.Equal(t, expected, h.tokens)
+
+	h = new(tokensHandler)
+	require.NoError(t, ParseReader(iotest.OneByteReader(strings.NewReader(data)), h))
+	require.Equal(t, expected, h.tokens)
+
+	// key is split from colon by chunks of reader
+	h = new(tokensHandler)
+	r := io.MultiReader(strings.NewReader(`{"abcdef"`), strings.NewReader(`:1, "x\ty":2}`))
+	require.NoError(t, ParseReader(r, h))
+	require.Equal(t, []string{"{", "k:abcdef", "n:1", "k:x\ty", "n:2", "}"}, h.tokens)
+
+	h = &tokensHandler{stopAt: "k:c"}
+	require.Equal(t, errStopTokens, Parse([]byte(data), h))
+	require.Equal(t, expected[:11], h.tokens)
+
+	counter := struct {
+		NopHandler
+	}{}
+	require.NoError(t, Parse([]byte(data), counter))
+
+	invalid := []string{
+		``, ` `, `{`, `[1,]`, `{"a":1,}`, `{"a"}`, `{1:2}`, `[1}`, `{"a":1]`, `"abc`, `01`,
+		`-`, `1.e3`, `tru`, `[1 2]`, `{"a":1 "b":2}`, `truex`, `1a`, strings.Repeat("[", iteratorMaxDepth+1),
+	}
+	for _, data := range invalid {
+		err := Parse([]byte(data), NopHandler{})
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: expected syntax error, actual %v", data, err)
+		}
+		// std scanner rejects the same documents
+		require.NotEqual(t, nil, std.Validate([]byte(data)))
+	}
+}
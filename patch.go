@@ -0,0 +1,216 @@
+package jessy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPatchTest is returned by ApplyPatch when value of test operation differs
+var ErrPatchTest = errors.New("json: patch test failed")
+
+var (
+	errPatchNotArray = errors.New("json: patch is not an array of operations")
+	errPatchMoveInto = errors.New("json: patch moves value into itself")
+)
+
+// patchOperation is an operation of JSON Patch, value is nil if it is missing
+type patchOperation struct {
+	op    string
+	path  string
+	from  string
+	value []byte
+}
+
+// ApplyPatch applies JSON Patch (RFC 6902) operations add, remove, replace, move,
+// copy and test to doc one by one and returns the new document. The document is
+// not decoded, each operation scans it up to its path and copies the rest
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	var it Iterator
+	it.Reset(doc)
+	it.Skip()
+	if err = it.End(); err != nil {
+		return nil, err
+	}
+	for i := range ops {
+		op := &ops[i]
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("json: patch operation %d %s %q: %w", i, op.op, op.path, err)
+		}
+	}
+	return doc, nil
+}
+
+func parsePatch(patch []byte) (ops []patchOperation, err error) {
+	var it Iterator
+	it.Reset(patch)
+	if it.Next() != KindArray {
+		if err = it.Error(); err == nil {
+			err = errPatchNotArray
+		}
+		return nil, err
+	}
+	it.ReadArray(func() bool {
+		if it.Next() != KindObject {
+			err = errPatchNotArray
+			return false
+		}
+		var op patchOperation
+		var hasPath, hasFrom bool
+		it.ReadObject(func(key []byte) bool {
+			switch string(key) {
+			case "op":
+				op.op = it.ReadString()
+			case "path":
+				op.path, hasPath = it.ReadString(), true
+			case "from":
+				op.from, hasFrom = it.ReadString(), true
+			case "value":
+				op.value = it.ReadRaw()
+			}
+			return true
+		})
+		if it.Error() != nil {
+			return false
+		}
+		var missing string
+		switch op.op {
+		case "add", "replace", "test":
+			if op.value == nil {
+				missing = "value"
+			}
+		case "move", "copy":
+			if !hasFrom {
+				missing = "from"
+			}
+		case "remove":
+		default:
+			err = fmt.Errorf("json: patch operation %d has invalid op %q", len(ops), op.op)
+			return false
+		}
+		if !hasPath {
+			missing = "path"
+		}
+		if missing != "" {
+			err = fmt.Errorf("json: patch operation %d %s has no %s", len(ops), op.op, missing)
+			return false
+		}
+		ops = append(ops, op)
+		return true
+	})
+	if err == nil {
+		err = it.End()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+func (op *patchOperation) apply(doc []byte) ([]byte, error) {
+	switch op.op {
+	case "add":
+		return editPointer(doc, op.path, editAdd, 0, appendRawValue(op.value))
+	case "remove":
+		return DeleteBytes(doc, op.path)
+	case "replace":
+		return editPointer(doc, op.path, editReplace, 0, appendRawValue(op.value))
+	case "move":
+		if op.from == op.path {
+			_, err := GetPointer(doc, op.from)
+			return doc, err
+		}
+		if strings.HasPrefix(op.path, op.from+"/") {
+			return nil, errPatchMoveInto
+		}
+		value, err := GetPointer(doc, op.from)
+		if err != nil {
+			return nil, err
+		}
+		// value points into doc which is not changed by deletion
+		if doc, err = DeleteBytes(doc, op.from); err != nil {
+			return nil, err
+		}
+		return editPointer(doc, op.path, editAdd, 0, appendRawValue(value))
+	case "copy":
+		value, err := GetPointer(doc, op.from)
+		if err != nil {
+			return nil, err
+		}
+		return editPointer(doc, op.path, editAdd, 0, appendRawValue(value))
+	case "test":
+		value, err := GetPointer(doc, op.path)
+		if err != nil {
+			return nil, err
+		}
+		if !pathEqual(value, op.value) {
+			return nil, ErrPatchTest
+		}
+	}
+	return doc, nil
+}
+
+func appendRawValue(value []byte) func(dst []byte) ([]byte, error) {
+	return func(dst []byte) ([]byte, error) {
+		return append(dst, value...), nil
+	}
+}
+
+// CreatePatch returns JSON Patch (RFC 6902) transforming document a into b.
+// Members of objects are compared by keys and elements of arrays by indexes,
+// changed scalars are replaced, extra elements are removed from the end
+func CreatePatch(a, b []byte) ([]byte, error) {
+	var it Iterator
+	it.Reset(a)
+	a = it.ReadRaw()
+	if err := it.End(); err != nil {
+		return nil, err
+	}
+	it.Reset(b)
+	b = it.ReadRaw()
+	if err := it.End(); err != nil {
+		return nil, err
+	}
+	var e pathEval
+	changes := e.diff(nil, nil, rawNode(a), rawNode(b))
+	if e.err != nil {
+		return nil, e.err
+	}
+	return DiffPatch(changes), nil
+}
+
+// rawObjectMembers returns keys of raw object in order and their values
+func rawObjectMembers(raw []byte) (keys []string, members map[string][]byte) {
+	members = make(map[string][]byte)
+	var it Iterator
+	it.Reset(raw)
+	it.ReadObject(func(key []byte) bool {
+		k := string(key)
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = it.ReadRaw()
+		return true
+	})
+	return
+}
+
+// appendPointerToken appends key to JSON pointer escaping ~ and /
+func appendPointerToken(ptr []byte, key string) []byte {
+	ptr = append(ptr, '/')
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case '~':
+			ptr = append(ptr, '~', '0')
+		case '/':
+			ptr = append(ptr, '~', '1')
+		default:
+			ptr = append(ptr, c)
+		}
+	}
+	return ptr
+}
@@ -0,0 +1,130 @@
+package jessy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		result string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"foo": "bar","baz":"qux"}`},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux","baz"]}`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo": ["bar",["abc", "def"]]}`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/1", "value": 1}]`, `{"foo": ["bar",1]}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/foo", "value": null}]`, `{"foo": null}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "", "value": [1]}]`, `[1]`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`},
+		{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`},
+		{
+			`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault","thud":"fred"}}`,
+		},
+		{
+			`{"foo": ["all", "grass", "cows", "eat"]}`,
+			`[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			`{"foo": ["all", "cows", "eat","grass"]}`,
+		},
+		{`{"a": {"b": 1}}`, `[{"op": "copy", "from": "/a", "path": "/c"}]`, `{"a": {"b": 1},"c":{"b": 1}}`},
+		{`{"a": 1}`, `[{"op": "move", "from": "/a", "path": "/a"}]`, `{"a": 1}`},
+		{
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`,
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}, {"op": "remove", "path": "/~1"}]`, `{"~1": 10}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, `{"foo": "bar","baz":"qux"}`},
+		{`[1, 2]`, `[]`, `[1, 2]`},
+	}
+	for _, c := range cases {
+		result, err := ApplyPatch([]byte(c.doc), []byte(c.patch))
+		require.NoError(t, err)
+		require.Equal(t, c.result, string(result))
+	}
+
+	failures := []struct {
+		doc   string
+		patch string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/2", "value": 1}]`},
+		{`{"foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`},
+		{`{"foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": 1}]`},
+		{`{"foo": "bar"}`, `[{"op": "move", "from": "/baz", "path": "/x"}]`},
+		{`{"foo": "bar"}`, `[{"op": "copy", "from": "/baz", "path": "/x"}]`},
+		{`{"foo": "bar"}`, `[{"op": "test", "path": "/baz", "value": 1}]`},
+	}
+	for _, c := range failures {
+		_, err := ApplyPatch([]byte(c.doc), []byte(c.patch))
+		if !errors.Is(err, ErrPointerNotFound) {
+			t.Fatalf("%s: expected not found error, actual %v", c.patch, err)
+		}
+	}
+	_, err := ApplyPatch([]byte(`{"baz": "qux"}`), []byte(`[{"op": "test", "path": "/baz", "value": "bar"}]`))
+	require.Equal(t, true, errors.Is(err, ErrPatchTest))
+
+	for _, c := range []struct {
+		doc   string
+		patch string
+	}{
+		{`{"a": {"b": 1}}`, `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`},
+		{`{"a": 1}`, `[{"op": "add", "path": "/b"}]`},
+		{`{"a": 1}`, `[{"op": "copy", "path": "/b"}]`},
+		{`{"a": 1}`, `[{"op": "remove"}]`},
+		{`{"a": 1}`, `[{"op": "get", "path": "/a"}]`},
+		{`{"a": 1}`, `{"op": "remove", "path": "/a"}`},
+		{`{"a": 1}`, `[1]`},
+		{`{"a": 1}`, `[{"op": "remove", "path": "/a"}`},
+		{`{"a": 1`, `[{"op": "remove", "path": "/a"}]`},
+		{`{"a": [1]}`, `[{"op": "add", "path": "/a/01", "value": 1}]`},
+	} {
+		_, err = ApplyPatch([]byte(c.doc), []byte(c.patch))
+		if err == nil || errors.Is(err, ErrPointerNotFound) {
+			t.Fatalf("%s: expected patch error, actual %v", c.patch, err)
+		}
+	}
+
+	// malformed patch is reported before missing members
+	for _, patch := range []string{`[{"op": "add", "path": "/b", "value":}]`, `[{"op": "add", "path": "/b", "value": 01}]`, `[{"op": "add", "path": 1, "value": 1}]`} {
+		_, err = ApplyPatch([]byte(`{"a": 1}`), []byte(patch))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%s: expected syntax error, actual %v", patch, err)
+		}
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		patch string
+	}{
+		{`{"a": 1}`, ` {"a": 1.0} `, `[]`},
+		{`{"a": 1, "b": {"c": [1, 2, 3], "d": "x"}}`, `{"b": {"c": [1, 5], "d": "x"}, "e": {"f": null}}`,
+			`[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/c/1","value":5},{"op":"remove","path":"/b/c/2"},{"op":"add","path":"/e","value":{"f": null}}]`},
+		{`[1]`, `[1, [2], 3]`, `[{"op":"add","path":"/1","value":[2]},{"op":"add","path":"/2","value":3}]`},
+		{`{"a/b": 1, "~": [1]}`, `{"a/b": 2, "~": {}}`, `[{"op":"replace","path":"/a~1b","value":2},{"op":"replace","path":"/~0","value":{}}]`},
+		{`1`, `"x"`, `[{"op":"replace","path":"","value":"x"}]`},
+	}
+	for _, c := range cases {
+		patch, err := CreatePatch([]byte(c.a), []byte(c.b))
+		require.NoError(t, err)
+		require.Equal(t, c.patch, string(patch))
+
+		result, err := ApplyPatch([]byte(c.a), patch)
+		require.NoError(t, err)
+		require.Equal(t, true, pathEqual(result, []byte(strings.TrimSpace(c.b))))
+	}
+	_, err := CreatePatch([]byte(`{`), []byte(`{}`))
+	require.NotEqual(t, nil, err)
+	_, err = CreatePatch([]byte(`{}`), []byte(`[] 1`))
+	require.NotEqual(t, nil, err)
+}
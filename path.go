@@ -0,0 +1,658 @@
+package jessy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+// Path is a compiled JSONPath expression (RFC 9535), e.g.
+// "$.store.book[?@.price < 10 && @.tags[*] == 'new'].title".
+// It is safe for concurrent use
+type Path struct {
+	expr  string
+	query pathQuery
+}
+
+// pathQuery is a list of segments applied to the root or current node
+type pathQuery struct {
+	relative bool
+	segments []pathSegment
+}
+
+// pathSegment selects children of its input nodes or of them and all their descendants
+type pathSegment struct {
+	descendant bool
+	selectors  []pathSelector
+}
+
+type selectorKind uint8
+
+const (
+	selectName selectorKind = iota
+	selectWildcard
+	selectIndex
+	selectSlice
+	selectFilter
+)
+
+type pathSelector struct {
+	kind   selectorKind
+	name   string
+	index  int64 // index or start of slice
+	end    int64
+	step   int64
+	start  bool // start of slice is set
+	hasEnd bool
+	filter *pathExpr
+}
+
+type exprKind uint8
+
+const (
+	exprOr exprKind = iota
+	exprAnd
+	exprNot
+	exprCompare
+	exprLiteral
+	exprQuery
+	exprFunc
+)
+
+// pathType is a type of filter expression by RFC 9535 type system
+type pathType uint8
+
+const (
+	typeValue pathType = iota
+	typeLogical
+	typeNodes
+)
+
+type pathExpr struct {
+	kind  exprKind
+	op    string // comparison operator or function name
+	args  []*pathExpr
+	lit   []byte // JSON of literal
+	query pathQuery
+	re    *regexp.Regexp // compiled literal pattern of match and search
+}
+
+// pathFunctions are types of parameters and results of standard functions
+var pathFunctions = map[string]struct {
+	params []pathType
+	result pathType
+}{
+	"length": {[]pathType{typeValue}, typeValue},
+	"count":  {[]pathType{typeNodes}, typeValue},
+	"match":  {[]pathType{typeValue, typeValue}, typeLogical},
+	"search": {[]pathType{typeValue, typeValue}, typeLogical},
+	"value":  {[]pathType{typeNodes}, typeValue},
+}
+
+// CompilePath parses JSONPath expression with name, wildcard, index, slice
+// and filter selectors, descendant segments and functions length, count,
+// match, search and value
+func CompilePath(expr string) (*Path, error) {
+	p := pathParser{expr: expr}
+	if !p.consume("$") {
+		p.fail("path does not start with $")
+	}
+	segments := p.parseSegments()
+	if p.err == nil && p.pos < len(expr) {
+		p.fail("unexpected character")
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Path{expr: expr, query: pathQuery{segments: segments}}, nil
+}
+
+// MustCompilePath is like CompilePath but panics on invalid expression
+func MustCompilePath(expr string) *Path {
+	p, err := CompilePath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns source expression of path
+func (p *Path) String() string {
+	return p.expr
+}
+
+// pathParser keeps the first error, next parsing does nothing
+type pathParser struct {
+	expr string
+	pos  int
+	err  error
+}
+
+func (p *pathParser) fail(msg string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("json: invalid path %q at offset %d: %s", p.expr, p.pos, msg)
+	}
+}
+
+func (p *pathParser) peek() byte {
+	if p.err != nil || p.pos >= len(p.expr) {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+func (p *pathParser) consume(s string) bool {
+	if p.err == nil && strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *pathParser) blanks() {
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseSegments parses segments separated by optional blanks
+func (p *pathParser) parseSegments() (segments []pathSegment) {
+	for p.err == nil {
+		start := p.pos
+		p.blanks()
+		c := p.peek()
+		if c != '.' && c != '[' {
+			p.pos = start
+			return
+		}
+		segments = append(segments, p.parseSegment())
+	}
+	return
+}
+
+func (p *pathParser) parseSegment() (seg pathSegment) {
+	if p.consume("[") {
+		seg.selectors = p.parseSelectors()
+		return
+	}
+	p.pos++
+	if p.consume(".") {
+		seg.descendant = true
+		if p.consume("[") {
+			seg.selectors = p.parseSelectors()
+			return
+		}
+	}
+	if p.consume("*") {
+		seg.selectors = []pathSelector{{kind: selectWildcard}}
+		return
+	}
+	name := p.parseName()
+	if name == "" {
+		p.fail("expected member name or *")
+	}
+	seg.selectors = []pathSelector{{kind: selectName, name: name}}
+	return
+}
+
+// parseName parses member name shorthand of letters, digits, _ and non-ASCII characters
+func (p *pathParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		switch {
+		case c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			p.pos++
+		case '0' <= c && c <= '9':
+			if p.pos == start {
+				return ""
+			}
+			p.pos++
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+			if r == utf8.RuneError && size == 1 {
+				p.fail("invalid UTF-8")
+				return ""
+			}
+			p.pos += size
+		default:
+			return p.expr[start:p.pos]
+		}
+	}
+	return p.expr[start:p.pos]
+}
+
+// parseSelectors parses comma separated selectors after [
+func (p *pathParser) parseSelectors() (selectors []pathSelector) {
+	for p.err == nil {
+		p.blanks()
+		selectors = append(selectors, p.parseSelector())
+		p.blanks()
+		switch {
+		case p.consume(","):
+		case p.consume("]"):
+			return
+		default:
+			p.fail("expected , or ]")
+		}
+	}
+	return
+}
+
+func (p *pathParser) parseSelector() (sel pathSelector) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		sel.name = p.parseString()
+	case c == '*':
+		p.pos++
+		sel.kind = selectWildcard
+	case c == '?':
+		p.pos++
+		p.blanks()
+		sel.kind = selectFilter
+		sel.filter = p.parseLogical()
+	case c == ':' || c == '-' || ('0' <= c && c <= '9'):
+		sel.kind = selectIndex
+		if c != ':' {
+			sel.index = p.parseInt()
+			sel.start = true
+			p.blanks()
+		}
+		if !p.consume(":") {
+			if c == ':' {
+				p.fail("expected slice")
+			}
+			return
+		}
+		sel.kind = selectSlice
+		sel.step = 1
+		p.blanks()
+		if c := p.peek(); c == '-' || ('0' <= c && c <= '9') {
+			sel.end = p.parseInt()
+			sel.hasEnd = true
+			p.blanks()
+		}
+		if p.consume(":") {
+			p.blanks()
+			if c := p.peek(); c == '-' || ('0' <= c && c <= '9') {
+				sel.step = p.parseInt()
+			}
+		}
+	default:
+		p.fail("expected selector")
+	}
+	return
+}
+
+// parseInt parses integer without leading zeros in range of exact float64 integers
+func (p *pathParser) parseInt() int64 {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for c := p.peek(); '0' <= c && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+	s := p.expr[start:p.pos]
+	switch {
+	case p.pos == digits:
+		p.fail("expected digit")
+		return 0
+	case p.expr[digits] == '0' && (p.pos-digits > 1 || digits > start):
+		p.fail("invalid integer " + s)
+		return 0
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n > 1<<53-1 || n < -(1<<53-1) {
+		p.fail("integer " + s + " is out of range")
+		return 0
+	}
+	return n
+}
+
+// parseString parses single or double quoted string literal
+func (p *pathParser) parseString() string {
+	quote := p.expr[p.pos]
+	p.pos++
+	var buf []byte
+	start := p.pos
+	for p.err == nil {
+		if p.pos >= len(p.expr) {
+			p.fail("unterminated string")
+			break
+		}
+		c := p.expr[p.pos]
+		if c == quote {
+			p.pos++
+			if buf == nil {
+				return p.expr[start : p.pos-1]
+			}
+			return string(buf)
+		}
+		if c < 0x20 {
+			p.fail("control character in string")
+			break
+		}
+		if c != '\\' {
+			if buf != nil {
+				buf = append(buf, c)
+			}
+			p.pos++
+			continue
+		}
+		if buf == nil {
+			buf = append([]byte{}, p.expr[start:p.pos]...)
+		}
+		p.pos++
+		switch e := p.peek(); e {
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case '/', '\\', quote:
+			buf = append(buf, e)
+		case 'u':
+			r := p.parseHex()
+			if utf16.IsSurrogate(r) {
+				if r >= 0xDC00 || !p.consume(`\`) || p.peek() != 'u' {
+					p.fail("invalid surrogate pair")
+					break
+				}
+				r = utf16.DecodeRune(r, p.parseHex())
+				if r == utf8.RuneError {
+					p.fail("invalid surrogate pair")
+					break
+				}
+			}
+			buf = utf8.AppendRune(buf, r)
+			continue
+		default:
+			p.fail("invalid escape")
+		}
+		p.pos++
+	}
+	return ""
+}
+
+// parseHex parses 4 hex digits after u of escape
+func (p *pathParser) parseHex() rune {
+	p.pos++
+	if p.pos+4 > len(p.expr) {
+		p.fail("invalid unicode escape")
+		return 0
+	}
+	n, err := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		p.fail("invalid unicode escape")
+		return 0
+	}
+	p.pos += 4
+	return rune(n)
+}
+
+func (p *pathParser) parseLogical() *pathExpr {
+	x := p.parseAnd()
+	for p.err == nil {
+		p.blanks()
+		if !p.consume("||") {
+			break
+		}
+		p.blanks()
+		x = &pathExpr{kind: exprOr, args: []*pathExpr{x, p.parseAnd()}}
+	}
+	return x
+}
+
+func (p *pathParser) parseAnd() *pathExpr {
+	x := p.parseBasic()
+	for p.err == nil {
+		p.blanks()
+		if !p.consume("&&") {
+			break
+		}
+		p.blanks()
+		x = &pathExpr{kind: exprAnd, args: []*pathExpr{x, p.parseBasic()}}
+	}
+	return x
+}
+
+// parseBasic parses parenthesized expression, comparison or test of query or function
+func (p *pathParser) parseBasic() *pathExpr {
+	if p.consume("!") {
+		p.blanks()
+		var x *pathExpr
+		if p.peek() == '(' {
+			x = p.parseParen()
+		} else {
+			x = p.parseTest(p.parseOperand())
+		}
+		return &pathExpr{kind: exprNot, args: []*pathExpr{x}}
+	}
+	if p.peek() == '(' {
+		return p.parseParen()
+	}
+	x := p.parseOperand()
+	start := p.pos
+	p.blanks()
+	for _, op := range [...]string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			p.blanks()
+			y := p.parseOperand()
+			p.checkComparable(x)
+			p.checkComparable(y)
+			return &pathExpr{kind: exprCompare, op: op, args: []*pathExpr{x, y}}
+		}
+	}
+	p.pos = start
+	return p.parseTest(x)
+}
+
+func (p *pathParser) parseParen() *pathExpr {
+	p.pos++
+	p.blanks()
+	x := p.parseLogical()
+	p.blanks()
+	if !p.consume(")") {
+		p.fail("expected )")
+	}
+	return x
+}
+
+// parseTest checks that operand is query or function of logical type
+func (p *pathParser) parseTest(x *pathExpr) *pathExpr {
+	if x != nil && x.kind != exprQuery && !(x.kind == exprFunc && pathFunctions[x.op].result != typeValue) {
+		p.fail("expected comparison")
+	}
+	return x
+}
+
+// checkComparable checks that operand is literal, singular query or function of value type
+func (p *pathParser) checkComparable(x *pathExpr) {
+	switch {
+	case x == nil:
+	case x.kind == exprQuery && !x.query.singular():
+		p.fail("query in comparison is not singular")
+	case x.kind == exprFunc && pathFunctions[x.op].result != typeValue:
+		p.fail(x.op + " result is not comparable")
+	}
+}
+
+// parseOperand parses literal, query or function
+func (p *pathParser) parseOperand() *pathExpr {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		return &pathExpr{kind: exprQuery, query: pathQuery{relative: c == '@', segments: p.parseSegments()}}
+	case c == '\'' || c == '"':
+		s := p.parseString()
+		return &pathExpr{kind: exprLiteral, lit: zstr.AppendQuotedString(nil, []byte(s), false)}
+	case c == '-' || ('0' <= c && c <= '9'):
+		return &pathExpr{kind: exprLiteral, lit: p.parseNumber()}
+	case 'a' <= c && c <= 'z':
+		for _, lit := range [...]string{"true", "false", "null"} {
+			if p.consume(lit) {
+				return &pathExpr{kind: exprLiteral, lit: []byte(lit)}
+			}
+		}
+		return p.parseFunc()
+	}
+	p.fail("expected literal, query or function")
+	return nil
+}
+
+// parseNumber parses JSON number also allowing -0
+func (p *pathParser) parseNumber() []byte {
+	start := p.pos
+	p.consume("-")
+	if !p.consume("0") {
+		if c := p.peek(); c < '1' || c > '9' {
+			p.fail("invalid number")
+			return nil
+		}
+		p.digits()
+	}
+	if p.consume(".") && !p.digits() {
+		p.fail("invalid number")
+	}
+	if p.consume("e") || p.consume("E") {
+		if !p.consume("-") {
+			p.consume("+")
+		}
+		if !p.digits() {
+			p.fail("invalid number")
+		}
+	}
+	return []byte(p.expr[start:p.pos])
+}
+
+func (p *pathParser) digits() bool {
+	start := p.pos
+	for c := p.peek(); '0' <= c && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// parseFunc parses function call and checks types of its arguments
+func (p *pathParser) parseFunc() *pathExpr {
+	start := p.pos
+	for c := p.peek(); ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '_'; c = p.peek() {
+		p.pos++
+	}
+	name := p.expr[start:p.pos]
+	fn, ok := pathFunctions[name]
+	if !ok {
+		p.pos = start
+		p.fail("unknown function " + name)
+		return nil
+	}
+	if !p.consume("(") {
+		p.fail("expected (")
+		return nil
+	}
+	x := &pathExpr{kind: exprFunc, op: name}
+	p.blanks()
+	for p.err == nil && !p.consume(")") {
+		if len(x.args) > 0 {
+			if !p.consume(",") {
+				p.fail("expected , or )")
+				break
+			}
+			p.blanks()
+		}
+		x.args = append(x.args, p.parseOperand())
+		p.blanks()
+	}
+	if p.err != nil {
+		return nil
+	}
+	if len(x.args) != len(fn.params) {
+		p.fail(fmt.Sprintf("%s needs %d arguments", name, len(fn.params)))
+		return nil
+	}
+	for i, arg := range x.args {
+		var ok bool
+		switch fn.params[i] {
+		case typeValue:
+			ok = arg.kind == exprLiteral ||
+				(arg.kind == exprQuery && arg.query.singular()) ||
+				(arg.kind == exprFunc && pathFunctions[arg.op].result == typeValue)
+		case typeNodes:
+			ok = arg.kind == exprQuery
+		}
+		if !ok {
+			p.fail(fmt.Sprintf("argument %d of %s has invalid type", i+1, name))
+			return nil
+		}
+	}
+	if (name == "match" || name == "search") && x.args[1].kind == exprLiteral {
+		if pattern, ok := pathString(x.args[1].lit); ok {
+			x.re = compilePathRegexp(pattern, name == "match")
+		}
+	}
+	return x
+}
+
+// singular reports query selecting at most one node
+func (q *pathQuery) singular() bool {
+	for i := range q.segments {
+		seg := &q.segments[i]
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		if k := seg.selectors[0].kind; k != selectName && k != selectIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// compilePathRegexp compiles I-Regexp (RFC 9485) pattern where dot
+// does not match line breaks, nil is returned for invalid pattern
+func compilePathRegexp(pattern string, full bool) *regexp.Regexp {
+	var b strings.Builder
+	if full {
+		b.WriteString(`^(?:`)
+	}
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteString(pattern[i : i+2])
+			i++
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+		case c == ']':
+			inClass = false
+			b.WriteByte(c)
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if full {
+		b.WriteString(`)$`)
+	}
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil
+	}
+	return re
+}
@@ -0,0 +1,679 @@
+package jessy
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/avpetkun/jessy-go/zgo"
+)
+
+// Select returns raw values of data matched by path in order of RFC 9535,
+// they point into data. Data is validated as a single JSON value
+func (p *Path) Select(data []byte) ([][]byte, error) {
+	var it Iterator
+	it.Reset(data)
+	raw := it.ReadRaw()
+	if err := it.End(); err != nil {
+		return nil, err
+	}
+	e := pathEval{root: rawNode(raw)}
+	nodes := e.query(&p.query, e.root)
+	matches := make([][]byte, len(nodes))
+	for i, n := range nodes {
+		matches[i] = n.(rawNode)
+	}
+	return matches, nil
+}
+
+// SelectValue returns JSON of parts of v matched by path as Marshal encodes them.
+// Structs are walked by fields of cached encoders without encoding of the whole value,
+// so omitted fields are not matched and fields are named by their json tags
+func (p *Path) SelectValue(v any) ([][]byte, error) {
+	var e pathEval
+	e.root = e.goValue(reflect.TypeFor[any](), unsafe.Pointer(&v), EncodeStandard, 0)
+	if e.root == nil {
+		e.root = rawNode("null")
+	}
+	nodes := e.query(&p.query, e.root)
+	if e.err != nil {
+		return nil, e.err
+	}
+	var buf []byte
+	ends := make([]int, len(nodes))
+	for i, n := range nodes {
+		buf = n.json(&e, buf)
+		ends[i] = len(buf)
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	matches := make([][]byte, len(nodes))
+	start := 0
+	for i, end := range ends {
+		matches[i] = buf[start:end:end]
+		start = end
+	}
+	return matches, nil
+}
+
+// pathNode is a JSON value of evaluated document, raw or Go
+type pathNode interface {
+	kind() Kind
+	// members calls f for members of object until f returns false
+	members(e *pathEval, f func(key []byte, v pathNode) bool)
+	// elements calls f for elements of array until f returns false
+	elements(e *pathEval, f func(v pathNode) bool)
+	json(e *pathEval, dst []byte) []byte
+}
+
+// pathEval keeps root node of query and the first encoding error of Go values
+type pathEval struct {
+	root pathNode
+	err  error
+}
+
+// fail keeps the first error of evaluation
+func (e *pathEval) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *pathEval) query(q *pathQuery, cur pathNode) []pathNode {
+	if !q.relative {
+		cur = e.root
+	}
+	nodes := []pathNode{cur}
+	for i := range q.segments {
+		seg := &q.segments[i]
+		var next []pathNode
+		for _, n := range nodes {
+			if seg.descendant {
+				next = e.descend(seg, n, next)
+			} else {
+				next = e.selectNodes(seg, n, next)
+			}
+		}
+		nodes = next
+		if len(nodes) == 0 || e.err != nil {
+			return nil
+		}
+	}
+	return nodes
+}
+
+// descend applies segment to node and then to its descendants in document order
+func (e *pathEval) descend(seg *pathSegment, n pathNode, dst []pathNode) []pathNode {
+	dst = e.selectNodes(seg, n, dst)
+	e.children(n, func(c pathNode) bool {
+		dst = e.descend(seg, c, dst)
+		return e.err == nil
+	})
+	return dst
+}
+
+func (e *pathEval) children(n pathNode, f func(c pathNode) bool) {
+	switch n.kind() {
+	case KindObject:
+		n.members(e, func(_ []byte, v pathNode) bool { return f(v) })
+	case KindArray:
+		n.elements(e, f)
+	}
+}
+
+func (e *pathEval) selectNodes(seg *pathSegment, n pathNode, dst []pathNode) []pathNode {
+	kind := n.kind()
+	for i := range seg.selectors {
+		sel := &seg.selectors[i]
+		switch sel.kind {
+		case selectName:
+			if kind == KindObject {
+				n.members(e, func(key []byte, v pathNode) bool {
+					if string(key) == sel.name {
+						dst = append(dst, v)
+						return false
+					}
+					return true
+				})
+			}
+		case selectWildcard:
+			e.children(n, func(c pathNode) bool {
+				dst = append(dst, c)
+				return true
+			})
+		case selectIndex:
+			if kind != KindArray {
+				continue
+			}
+			index := sel.index
+			if index < 0 {
+				items := e.items(n)
+				if index += int64(len(items)); index >= 0 {
+					dst = append(dst, items[index])
+				}
+				continue
+			}
+			i := int64(0)
+			n.elements(e, func(v pathNode) bool {
+				if i == index {
+					dst = append(dst, v)
+					return false
+				}
+				i++
+				return true
+			})
+		case selectSlice:
+			if kind == KindArray {
+				dst = sel.slice(e.items(n), dst)
+			}
+		case selectFilter:
+			e.children(n, func(c pathNode) bool {
+				if e.test(sel.filter, c) {
+					dst = append(dst, c)
+				}
+				return e.err == nil
+			})
+		}
+	}
+	return dst
+}
+
+func (e *pathEval) items(n pathNode) (items []pathNode) {
+	n.elements(e, func(v pathNode) bool {
+		items = append(items, v)
+		return true
+	})
+	return
+}
+
+// slice appends items selected by slice with normalized bounds
+func (sel *pathSelector) slice(items, dst []pathNode) []pathNode {
+	n := int64(len(items))
+	normalize := func(i int64) int64 {
+		if i < 0 {
+			return n + i
+		}
+		return i
+	}
+	switch {
+	case sel.step > 0:
+		lower, upper := int64(0), n
+		if sel.start {
+			lower = min(max(normalize(sel.index), 0), n)
+		}
+		if sel.hasEnd {
+			upper = min(max(normalize(sel.end), 0), n)
+		}
+		for i := lower; i < upper; i += sel.step {
+			dst = append(dst, items[i])
+		}
+	case sel.step < 0:
+		upper, lower := n-1, int64(-1)
+		if sel.start {
+			upper = min(max(normalize(sel.index), -1), n-1)
+		}
+		if sel.hasEnd {
+			lower = min(max(normalize(sel.end), -1), n-1)
+		}
+		for i := upper; lower < i; i += sel.step {
+			dst = append(dst, items[i])
+		}
+	}
+	return dst
+}
+
+// test evaluates logical expression for current node
+func (e *pathEval) test(x *pathExpr, cur pathNode) bool {
+	switch x.kind {
+	case exprOr:
+		return e.test(x.args[0], cur) || e.test(x.args[1], cur)
+	case exprAnd:
+		return e.test(x.args[0], cur) && e.test(x.args[1], cur)
+	case exprNot:
+		return !e.test(x.args[0], cur)
+	case exprCompare:
+		a := e.json(e.value(x.args[0], cur))
+		b := e.json(e.value(x.args[1], cur))
+		return pathCompare(x.op, a, b)
+	case exprQuery:
+		return len(e.query(&x.query, cur)) > 0
+	case exprFunc:
+		return e.match(x, cur)
+	}
+	return false
+}
+
+// value evaluates literal, singular query or function for current node, nil is nothing
+func (e *pathEval) value(x *pathExpr, cur pathNode) pathNode {
+	switch x.kind {
+	case exprLiteral:
+		return rawNode(x.lit)
+	case exprQuery:
+		if nodes := e.query(&x.query, cur); len(nodes) == 1 {
+			return nodes[0]
+		}
+	case exprFunc:
+		switch x.op {
+		case "length":
+			return e.length(e.value(x.args[0], cur))
+		case "count":
+			nodes := e.query(&x.args[0].query, cur)
+			return rawNode(strconv.AppendInt(nil, int64(len(nodes)), 10))
+		case "value":
+			return e.value(x.args[0], cur)
+		}
+	}
+	return nil
+}
+
+func (e *pathEval) json(n pathNode) []byte {
+	if n == nil {
+		return nil
+	}
+	return n.json(e, nil)
+}
+
+// length returns count of characters of string, elements of array or members of object
+func (e *pathEval) length(n pathNode) pathNode {
+	if n == nil {
+		return nil
+	}
+	count := 0
+	switch n.kind() {
+	case KindString:
+		s, _ := pathString(n.json(e, nil))
+		count = utf8.RuneCountInString(s)
+	case KindArray, KindObject:
+		e.children(n, func(pathNode) bool {
+			count++
+			return true
+		})
+	default:
+		return nil
+	}
+	return rawNode(strconv.AppendInt(nil, int64(count), 10))
+}
+
+// match evaluates match or search function
+func (e *pathEval) match(x *pathExpr, cur pathNode) bool {
+	s, ok := pathString(e.json(e.value(x.args[0], cur)))
+	if !ok {
+		return false
+	}
+	re := x.re
+	if re == nil {
+		pattern, ok := pathString(e.json(e.value(x.args[1], cur)))
+		if !ok {
+			return false
+		}
+		if re = compilePathRegexp(pattern, x.op == "match"); re == nil {
+			return false
+		}
+	}
+	return re.MatchString(s)
+}
+
+// pathString decodes JSON string
+func pathString(raw []byte) (string, bool) {
+	if len(raw) == 0 || raw[0] != '"' {
+		return "", false
+	}
+	var it Iterator
+	it.Reset(raw)
+	s := it.ReadString()
+	return s, it.err == nil
+}
+
+// pathCompare compares JSON values, nil is nothing equal only to nothing
+func pathCompare(op string, a, b []byte) bool {
+	switch op {
+	case "==":
+		return pathEqual(a, b)
+	case "!=":
+		return !pathEqual(a, b)
+	case "<":
+		return pathLess(a, b)
+	case "<=":
+		return pathLess(a, b) || pathEqual(a, b)
+	case ">":
+		return pathLess(b, a)
+	case ">=":
+		return pathLess(b, a) || pathEqual(a, b)
+	}
+	return false
+}
+
+// pathLess compares numbers by values and strings by code points
+func pathLess(a, b []byte) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	switch ka, kb := kindOfByte[a[0]], kindOfByte[b[0]]; {
+	case ka == KindNumber && kb == KindNumber:
+		x, _ := strconv.ParseFloat(string(a), 64)
+		y, _ := strconv.ParseFloat(string(b), 64)
+		return x < y
+	case ka == KindString && kb == KindString:
+		x, _ := pathString(a)
+		y, _ := pathString(b)
+		return x < y
+	}
+	return false
+}
+
+// pathEqual compares JSON values deeply, numbers by values and objects regardless of order
+func pathEqual(a, b []byte) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	kind := kindOfByte[a[0]]
+	if kind != kindOfByte[b[0]] {
+		return false
+	}
+	switch kind {
+	case KindNumber:
+		x, _ := strconv.ParseFloat(string(a), 64)
+		y, _ := strconv.ParseFloat(string(b), 64)
+		return x == y
+	case KindString:
+		x, _ := pathString(a)
+		y, _ := pathString(b)
+		return x == y
+	case KindArray:
+		var e pathEval
+		x, y := e.items(rawNode(a)), e.items(rawNode(b))
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !pathEqual(x[i].(rawNode), y[i].(rawNode)) {
+				return false
+			}
+		}
+		return true
+	case KindObject:
+		members := func(raw []byte) map[string][]byte {
+			m := make(map[string][]byte)
+			var e pathEval
+			rawNode(raw).members(&e, func(key []byte, v pathNode) bool {
+				m[string(key)] = v.(rawNode)
+				return true
+			})
+			return m
+		}
+		x, y := members(a), members(b)
+		if len(x) != len(y) {
+			return false
+		}
+		for key, v := range x {
+			if w, ok := y[key]; !ok || !pathEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return bytes.Equal(a, b)
+}
+
+// rawNode is a valid JSON value without surrounding spaces
+type rawNode []byte
+
+func (n rawNode) kind() Kind {
+	return kindOfByte[n[0]]
+}
+
+func (n rawNode) members(e *pathEval, f func(key []byte, v pathNode) bool) {
+	if n.kind() != KindObject {
+		return
+	}
+	var it Iterator
+	it.Reset(n)
+	it.ReadObject(func(key []byte) bool {
+		return f(key, rawNode(it.ReadRaw()))
+	})
+}
+
+func (n rawNode) elements(e *pathEval, f func(v pathNode) bool) {
+	if n.kind() != KindArray {
+		return
+	}
+	var it Iterator
+	it.Reset(n)
+	it.ReadArray(func() bool {
+		return f(rawNode(it.ReadRaw()))
+	})
+}
+
+func (n rawNode) json(e *pathEval, dst []byte) []byte {
+	if dst == nil {
+		return n
+	}
+	return append(dst, n...)
+}
+
+var errPathTooDeep = errors.New("json: exceeded max depth of value, it may be cyclic")
+
+// goNode is a struct, map, slice or array of Go value at ptr
+type goNode struct {
+	typ   reflect.Type
+	ptr   unsafe.Pointer
+	flags Flags
+	depth int
+}
+
+// goValue returns node of value of type t at ptr dereferencing pointers and interfaces.
+// Values encoded without structure are encoded at once, nil is returned if they are omitted.
+// Nodes deeper than iteratorMaxDepth fail as cyclic values would never end
+func (e *pathEval) goValue(t reflect.Type, ptr unsafe.Pointer, flags Flags, depth int) pathNode {
+	if depth >= iteratorMaxDepth {
+		e.fail(errPathTooDeep)
+		return nil
+	}
+	for refs := 0; !tStreamLeaf(t); refs++ {
+		if refs == iteratorMaxDepth {
+			e.fail(errPathTooDeep)
+			return nil
+		}
+		if t.Kind() == reflect.Pointer {
+			p := *(*unsafe.Pointer)(ptr)
+			if p == nil {
+				break
+			}
+			t, ptr, flags = t.Elem(), p, flags.Exclude(OmitEmpty)
+		} else if t.Kind() == reflect.Interface {
+			v := reflect.NewAt(t, ptr).Elem()
+			if v.IsNil() {
+				break
+			}
+			v = v.Elem()
+			c := reflect.New(v.Type())
+			c.Elem().Set(v)
+			t, ptr = v.Type(), c.UnsafePointer()
+		} else {
+			break
+		}
+	}
+	if e.structural(t, ptr, flags) {
+		return &goNode{t, ptr, flags, depth}
+	}
+	raw, err := encodeAt(nil, t, flags, ptr)
+	if err != nil {
+		e.fail(err)
+		return nil
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return rawNode(raw)
+}
+
+// structural reports value encoded as object or array of other values
+func (e *pathEval) structural(t reflect.Type, ptr unsafe.Pointer, flags Flags) bool {
+	if tStreamLeaf(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Map:
+		return *(*unsafe.Pointer)(ptr) != nil
+	case reflect.Slice:
+		elem := t.Elem()
+		isBytes := elem.Kind() == reflect.Uint8 && !tImplementsAny(elem) && !flags.Has(BytesArray)
+		return !isBytes && (*zgo.Slice)(ptr).Len != 0
+	case reflect.Array:
+		elem := t.Elem()
+		return !(elem.Kind() == reflect.Uint8 && !tImplementsAny(elem) && flags.Has(bytesStringFlags))
+	}
+	return false
+}
+
+func (n *goNode) kind() Kind {
+	switch n.typ.Kind() {
+	case reflect.Struct, reflect.Map:
+		return KindObject
+	}
+	return KindArray
+}
+
+func (n *goNode) members(e *pathEval, f func(key []byte, v pathNode) bool) {
+	switch n.typ.Kind() {
+	case reflect.Struct:
+		e.structMembers(pathStructFields(n.typ, n.flags, false), n.ptr, n.depth+1, f)
+	case reflect.Map:
+		e.mapMembers(n, f)
+	}
+}
+
+// structMembers calls f for present fields flattening embedded structs, false is returned when f stops
+func (e *pathEval) structMembers(fields []StructField, ptr unsafe.Pointer, depth int, f func(key []byte, v pathNode) bool) bool {
+	for i := range fields {
+		field := &fields[i]
+		slot := unsafe.Add(ptr, field.Offset)
+		if field.KeyLen == 0 {
+			t := field.typ
+			if t.Kind() == reflect.Pointer {
+				if slot = *(*unsafe.Pointer)(slot); slot == nil {
+					continue
+				}
+				t = t.Elem()
+			}
+			if !e.structMembers(pathStructFields(t, field.flags, true), slot, depth, f) {
+				return false
+			}
+			continue
+		}
+		v := e.goValue(field.typ, slot, field.flags, depth)
+		if v == nil {
+			if e.err != nil {
+				return false
+			}
+			continue
+		}
+		if !f(zgo.S2B(field.name()), v) {
+			return false
+		}
+	}
+	return true
+}
+
+// mapMembers calls f for entries of map in order of encoded keys
+func (e *pathEval) mapMembers(n *goNode, f func(key []byte, v pathNode) bool) {
+	m := reflect.NewAt(n.typ, n.ptr).Elem()
+	keys := m.MapKeys()
+	names := make([]string, len(keys))
+	keyType := n.typ.Key()
+	for i, key := range keys {
+		if keyType.Kind() == reflect.String && !tImplementsAny(keyType) {
+			names[i] = key.String()
+			continue
+		}
+		c := reflect.New(keyType)
+		c.Elem().Set(key)
+		raw, err := encodeAt(nil, keyType, n.flags.Exclude(OmitEmpty)|NeedQuotes, c.UnsafePointer())
+		if err != nil {
+			e.fail(err)
+			return
+		}
+		names[i], _ = pathString(raw)
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return names[order[i]] < names[order[j]] })
+
+	elemType := n.typ.Elem()
+	for _, i := range order {
+		c := reflect.New(elemType)
+		c.Elem().Set(m.MapIndex(keys[i]))
+		v := e.goValue(elemType, c.UnsafePointer(), n.flags.Exclude(OmitEmpty), n.depth+1)
+		if v == nil {
+			if e.err != nil {
+				return
+			}
+			continue
+		}
+		if !f(zgo.S2B(names[i]), v) {
+			return
+		}
+	}
+}
+
+func (n *goNode) elements(e *pathEval, f func(v pathNode) bool) {
+	var data unsafe.Pointer
+	var count int
+	if n.typ.Kind() == reflect.Slice {
+		h := (*zgo.Slice)(n.ptr)
+		data, count = h.Data, int(h.Len)
+	} else {
+		data, count = n.ptr, n.typ.Len()
+	}
+	elemType := n.typ.Elem()
+	size := elemType.Size()
+	for i := range count {
+		v := e.goValue(elemType, unsafe.Add(data, uintptr(i)*size), n.flags.Exclude(OmitEmpty), n.depth+1)
+		if v == nil {
+			if e.err != nil {
+				return
+			}
+			continue
+		}
+		if !f(v) {
+			return
+		}
+	}
+}
+
+func (n *goNode) json(e *pathEval, dst []byte) []byte {
+	dst, err := encodeAt(dst, n.typ, n.flags.Exclude(OmitEmpty), n.ptr)
+	if err != nil {
+		e.fail(err)
+	}
+	return dst
+}
+
+type pathFieldsKey struct {
+	typ      reflect.Type
+	flags    Flags
+	embedded bool
+}
+
+var pathFieldsCache sync.Map
+
+// pathStructFields returns cached fields of struct encoder sorted by keys
+func pathStructFields(t reflect.Type, flags Flags, embedded bool) []StructField {
+	key := pathFieldsKey{t, flags, embedded}
+	if fields, ok := pathFieldsCache.Load(key); ok {
+		return fields.([]StructField)
+	}
+	fields := getStructFields(0, 0, flags, t, true, embedded)
+	pathFieldsCache.Store(key, fields)
+	return fields
+}
@@ -0,0 +1,220 @@
+package jessy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+var pathStore = []byte(` { "store": {
+	"book": [
+		{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+		{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+		{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+		{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+	],
+	"bicycle": {"color": "red", "price": 399}
+} } `)
+
+func joinMatches(matches [][]byte) string {
+	return string(bytes.Join(matches, []byte(" ")))
+}
+
+func TestPathSelect(t *testing.T) {
+	cases := []struct {
+		expr    string
+		matches string
+	}{
+		{`$`, string(bytes.TrimSpace(pathStore))},
+		{`$.store.book[*].author`, `"Nigel Rees" "Evelyn Waugh" "Herman Melville" "J. R. R. Tolkien"`},
+		{`$..author`, `"Nigel Rees" "Evelyn Waugh" "Herman Melville" "J. R. R. Tolkien"`},
+		{`$.store.*.color`, `"red"`},
+		{`$.store..price`, `8.95 12.99 8.99 22.99 399`},
+		{`$..book[2].title`, `"Moby Dick"`},
+		{`$..book[-1].title`, `"The Lord of the Rings"`},
+		{`$..book[-5]`, ``},
+		{`$..book[0,1].price`, `8.95 12.99`},
+		{`$..book[:2].price`, `8.95 12.99`},
+		{`$..book[::-2].price`, `22.99 12.99`},
+		{`$..book[1:-1:1].price`, `12.99 8.99`},
+		{`$..book[0:4:0]`, ``},
+		{`$..book[?@.isbn].title`, `"Moby Dick" "The Lord of the Rings"`},
+		{`$..book[?@.price<10].title`, `"Sayings of the Century" "Moby Dick"`},
+		{`$..book[?!@.isbn && @.price > 10].author`, `"Evelyn Waugh"`},
+		{`$..book[?(@.price == 8.95 || @.price == 22.99)].price`, `8.95 22.99`},
+		{`$..book[?@.author == 'Herman Melville'].price`, `8.99`},
+		{`$..book[?@.price >= $.store.book[1].price].title`, `"Sword of Honour" "The Lord of the Rings"`},
+		{`$..book[?match(@.author, 'J.*')].title`, `"The Lord of the Rings"`},
+		{`$..book[?search(@.title, "of the")].price`, `8.95 22.99`},
+		{`$..book[?length(@.title) == 9].title`, `"Moby Dick"`},
+		{`$.store[?count(@.*) == 2].color`, `"red"`},
+		{`$.store[?value(@..color) == "red"].price`, `399`},
+		{`$["store"]['bicycle'][ 'color' , "price" ]`, `"red" 399`},
+		{`$.store.bicycle.missing`, ``},
+		{`$.store.book.title`, ``},
+	}
+	for _, c := range cases {
+		matches, err := MustCompilePath(c.expr).Select(pathStore)
+		require.NoError(t, err)
+		require.Equal(t, c.matches, joinMatches(matches))
+	}
+}
+
+func TestPathSelectValues(t *testing.T) {
+	data := []byte(`{"a": [1, 1.0, "1", [1, {"x": null}], {"y": [2], "x": true}, -0, "é"], "o": {"n": 1, "s": "xé"}}`)
+	cases := []struct {
+		expr    string
+		matches string
+	}{
+		{`$.a[?@ == 1]`, `1 1.0`},
+		{`$.a[?@ == '1']`, `"1"`},
+		{`$.a[?@ == 0]`, `-0`},
+		{`$.a[?@ == $.a[3]]`, `[1, {"x": null}]`},
+		{`$.a[?@ == $.a[4]]`, `{"y": [2], "x": true}`},
+		{`$.a[?@ < 2]`, `1 1.0 -0`},
+		{`$.a[?@ > 'a']`, `"é"`},
+		{`$.a[?@.x]`, `{"y": [2], "x": true}`},
+		{`$.a[?@.missing == $.missing]`, `1 1.0 "1" [1, {"x": null}] {"y": [2], "x": true} -0 "é"`},
+		{`$.a[?@.missing <= $.missing]`, `1 1.0 "1" [1, {"x": null}] {"y": [2], "x": true} -0 "é"`},
+		{`$.a[?@.missing < 1]`, ``},
+		{`$.a[?@.y[0] == 2].x`, `true`},
+		{`$.a[?@[1].x == null][0]`, `1`},
+		{`$.a[?length(@) == 1]`, `"1" "é"`},
+		{`$.o[?@ == 'xé']`, `"xé"`},
+		{`$..x`, `null true`},
+	}
+	for _, c := range cases {
+		matches, err := MustCompilePath(c.expr).Select(data)
+		require.NoError(t, err)
+		require.Equal(t, c.matches, joinMatches(matches))
+	}
+	matches, err := MustCompilePath(`$..*`).Select(data)
+	require.NoError(t, err)
+	require.Equal(t, 17, len(matches))
+
+	for _, data := range []string{``, `{"a": 1`, `[1] [2]`} {
+		_, err = MustCompilePath(`$.a`).Select([]byte(data))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: expected syntax error, actual %v", data, err)
+		}
+	}
+}
+
+func TestCompilePath(t *testing.T) {
+	for _, expr := range []string{
+		`$`, `$.a`, `$..*`, `$['a','b'][0,-1][1:2][::-1][*]`, `$[?@.a && (@.b || !@.c)]`,
+		`$[?length(@.a) > count(@.*)]`, `$[?match(@.a, $.p)]`, `$.é_1`, `$ .a [0]`,
+		`$[?@.a == -0.5e+3]`, `$["☺😀'\""]`, `$[?value(@..a) == true]`,
+	} {
+		p, err := CompilePath(expr)
+		require.NoError(t, err)
+		require.Equal(t, expr, p.String())
+	}
+	for _, expr := range []string{
+		``, `a`, `$.`, `$a`, `$.1a`, `$[`, `$[]`, `$[0`, `$[01]`, `$[-0]`, `$[9007199254740992]`,
+		`$['a]`, `$['\x']`, `$["\'"]`, `$['\ud800']`, `$[?@.a ==]`, `$[?1]`, `$[?@.* == 1]`,
+		`$[?@..a == 1]`, `$[?length(@.*) == 1]`, `$[?count(1) == 1]`, `$[?length(@.a)]`,
+		`$[?match(@.a) == true]`, `$[?foo(@.a)]`, `$[?!@.a == 1]`, `$ `, `$[?@.a == 01]`,
+	} {
+		if _, err := CompilePath(expr); err == nil {
+			t.Fatalf("%q: expected error", expr)
+		}
+	}
+}
+
+type pathUser struct {
+	pathBase
+	Name    string            `json:"name"`
+	Email   string            `json:"email,omitempty"`
+	Age     int               `json:"age,string"`
+	Tags    []string          `json:"tags"`
+	Friends []*pathUser       `json:"friends,omitempty"`
+	Attrs   map[string]any    `json:"attrs,omitempty"`
+	Scores  map[int]float64   `json:"scores"`
+	Created time.Time         `json:"created"`
+	Raw     RawMessage        `json:"raw,omitempty"`
+	Data    []byte            `json:"data"`
+	Skip    string            `json:"-"`
+	Extra   map[string]string `json:"extra"`
+}
+
+type pathBase struct {
+	ID int `json:"id"`
+}
+
+func TestPathSelectValue(t *testing.T) {
+	user := &pathUser{
+		pathBase: pathBase{ID: 1},
+		Name:     "ann", Age: 30, Tags: []string{"a", "b"},
+		Friends: []*pathUser{{pathBase: pathBase{ID: 2}, Name: "bob", Email: "bob@x", Tags: []string{"c"}}, nil},
+		Attrs:   map[string]any{"z": 1, "a": []any{true, "x y"}},
+		Scores:  map[int]float64{10: 1.5, 2: 3},
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Raw:     RawMessage(`{"k": [1]}`),
+		Data:    []byte("hi"),
+		Skip:    "skip",
+	}
+	cases := []struct {
+		expr    string
+		matches string
+	}{
+		{`$.name`, `"ann"`},
+		{`$.id`, `1`},
+		{`$.age`, `"30"`},
+		{`$.email`, ``},
+		{`$.fn`, ``},
+		{`$.Skip`, ``},
+		{`$.tags[-1]`, `"b"`},
+		{`$.friends[0].name`, `"bob"`},
+		{`$.friends[1]`, `null`},
+		{`$.friends[?@.email].id`, `2`},
+		{`$.attrs.*`, `[true,"x y"] 1`},
+		{`$.attrs.a[?@ == 'x y']`, `"x y"`},
+		{`$.scores`, `{"10":1.5,"2":3}`},
+		{`$.scores['2']`, `3`},
+		{`$.created`, `"2024-01-02T03:04:05Z"`},
+		{`$.raw`, `{"k":[1]}`},
+		{`$.raw.k`, `[1]`},
+		{`$.data`, `"aGk="`},
+		{`$.extra`, `null`},
+		{`$..name`, `"ann" "bob"`},
+		{`$..id`, `1 2`},
+		{`$.friends[0]`, `{"id":2,"age":"0","created":"0001-01-01T00:00:00Z","data":[],"email":"bob@x","extra":null,"name":"bob","raw":null,"scores":null,"tags":["c"]}`},
+		{`$[?@ == 'ann']`, `"ann"`},
+		{`$[?length(@) == 4]`, `"aGk="`},
+	}
+	for _, c := range cases {
+		matches, err := MustCompilePath(c.expr).SelectValue(user)
+		require.NoError(t, err)
+		require.Equal(t, c.matches, joinMatches(matches))
+	}
+	// matches are the same as of encoded value
+	data, err := Marshal(user)
+	require.NoError(t, err)
+	for _, c := range cases {
+		matches, err := MustCompilePath(c.expr).Select(data)
+		require.NoError(t, err)
+		require.Equal(t, c.matches, joinMatches(matches))
+	}
+
+	matches, err := MustCompilePath(`$[1].a`).SelectValue([]any{nil, map[string]int{"a": 5}})
+	require.NoError(t, err)
+	require.Equal(t, `5`, joinMatches(matches))
+	matches, err = MustCompilePath(`$`).SelectValue(nil)
+	require.NoError(t, err)
+	require.Equal(t, `null`, joinMatches(matches))
+
+	// cyclic values fail instead of endless walking
+	cyclic := &pathUser{Name: "loop"}
+	cyclic.Friends = []*pathUser{cyclic}
+	_, err = MustCompilePath(`$..name`).SelectValue(cyclic)
+	require.Equal(t, errPathTooDeep, err)
+	var loop any
+	loop = &loop
+	_, err = MustCompilePath(`$`).SelectValue(loop)
+	require.Equal(t, errPathTooDeep, err)
+}
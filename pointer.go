@@ -0,0 +1,257 @@
+package jessy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrPointerNotFound is returned when JSON pointer refers to missing key or index
+var ErrPointerNotFound = errors.New("json: pointer is not found")
+
+// GetPointer returns raw value of data at JSON pointer ptr (RFC 6901),
+// e.g. "/data/items/3/id", "" is the whole value. Values before the target
+// are skipped without decoding and values after it are not read at all
+func GetPointer(data []byte, ptr string) ([]byte, error) {
+	var it Iterator
+	it.Reset(data)
+	if err := it.seekPointer(ptr); err != nil {
+		return nil, err
+	}
+	raw := it.ReadRaw()
+	return raw, it.err
+}
+
+// GetString returns string value of data at JSON pointer ptr
+func GetString(data []byte, ptr string) (string, error) {
+	var it Iterator
+	it.Reset(data)
+	if err := it.seekPointer(ptr); err != nil {
+		return "", err
+	}
+	s := it.ReadStringBytes()
+	if it.err != nil {
+		return "", it.err
+	}
+	return string(s), nil
+}
+
+// GetInt64 returns integer value of data at JSON pointer ptr
+func GetInt64(data []byte, ptr string) (int64, error) {
+	var it Iterator
+	it.Reset(data)
+	if err := it.seekPointer(ptr); err != nil {
+		return 0, err
+	}
+	v := it.ReadInt64()
+	return v, it.err
+}
+
+// GetUint64 returns unsigned integer value of data at JSON pointer ptr
+func GetUint64(data []byte, ptr string) (uint64, error) {
+	var it Iterator
+	it.Reset(data)
+	if err := it.seekPointer(ptr); err != nil {
+		return 0, err
+	}
+	v := it.ReadUint64()
+	return v, it.err
+}
+
+// GetFloat64 returns number value of data at JSON pointer ptr
+func GetFloat64(data []byte, ptr string) (float64, error) {
+	var it Iterator
+	it.Reset(data)
+	if err := it.seekPointer(ptr); err != nil {
+		return 0, err
+	}
+	v := it.ReadFloat64()
+	return v, it.err
+}
+
+// GetBool returns bool value of data at JSON pointer ptr
+func GetBool(data []byte, ptr string) (bool, error) {
+	var it Iterator
+	it.Reset(data)
+	if err := it.seekPointer(ptr); err != nil {
+		return false, err
+	}
+	v := it.ReadBool()
+	return v, it.err
+}
+
+// UnmarshalAt decodes value of data at JSON pointer ptr into v
+func UnmarshalAt(data []byte, ptr string, v any) error {
+	raw, err := GetPointer(data, ptr)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(raw, v)
+}
+
+// seekPointer moves iterator to the value at pointer
+func (it *Iterator) seekPointer(ptr string) error {
+	if err := checkPointer(ptr); err != nil {
+		return err
+	}
+	for ptr != "" {
+		var token string
+		token, ptr = nextPointerToken(ptr)
+
+		var found bool
+		switch c := it.peek(); c {
+		case '{':
+			_, found = it.seekKey(token)
+		case '[':
+			index, ok := parsePointerIndex(token)
+			if !ok && token != "-" {
+				return errPointerIndex(token)
+			}
+			if ok {
+				_, found = it.seekIndex(index)
+			}
+		case 0:
+			if it.err == nil {
+				it.failAt(c, "looking for beginning of value")
+			}
+		}
+		if it.err != nil {
+			return it.err
+		}
+		if !found {
+			return ErrPointerNotFound
+		}
+	}
+	return nil
+}
+
+// nextPointerToken splits the first token of non-empty pointer
+func nextPointerToken(ptr string) (token, rest string) {
+	token = ptr[1:]
+	if i := strings.IndexByte(token, '/'); i >= 0 {
+		return token[:i], token[i:]
+	}
+	return token, ""
+}
+
+func errPointerIndex(token string) error {
+	return fmt.Errorf("json: invalid array index %q of pointer", token)
+}
+
+// seekKey moves iterator from object start to the value of key and returns
+// offset of the key, false is returned at the closing brace of object
+func (it *Iterator) seekKey(token string) (int, bool) {
+	it.pos++
+	if it.peek() == '}' {
+		return 0, false
+	}
+	for {
+		if c := it.peek(); c != '"' {
+			it.failAt(c, "looking for beginning of object key string")
+			return 0, false
+		}
+		keyPos := it.pos
+		key := it.scanString(&it.key)
+		if it.err != nil {
+			return 0, false
+		}
+		if c := it.peek(); c != ':' {
+			it.failAt(c, "after object key")
+			return 0, false
+		}
+		it.pos++
+		if pointerTokenEqual(key, token) {
+			return keyPos, true
+		}
+		it.skip(0)
+		switch c := it.peek(); c {
+		case ',':
+			it.pos++
+		case '}':
+			return 0, false
+		default:
+			if it.err == nil {
+				it.failAt(c, "after object key:value pair")
+			}
+			return 0, false
+		}
+	}
+}
+
+// seekIndex moves iterator from array start to the element at index and returns
+// count of elements before it, false is returned at the closing bracket of shorter array
+func (it *Iterator) seekIndex(index int) (int, bool) {
+	it.pos++
+	if it.peek() == ']' {
+		return 0, false
+	}
+	for i := 0; i != index; i++ {
+		it.skip(0)
+		switch c := it.peek(); c {
+		case ',':
+			it.pos++
+		case ']':
+			return i + 1, false
+		default:
+			if it.err == nil {
+				it.failAt(c, "after array element")
+			}
+			return i, false
+		}
+	}
+	return index, true
+}
+
+// checkPointer checks that pointer starts with slash and has only ~0 and ~1 escapes
+func checkPointer(ptr string) error {
+	if ptr != "" && ptr[0] != '/' {
+		return fmt.Errorf("json: pointer %q does not start with /", ptr)
+	}
+	for i := 0; i < len(ptr); i++ {
+		if ptr[i] == '~' && (i+1 == len(ptr) || (ptr[i+1] != '0' && ptr[i+1] != '1')) {
+			return fmt.Errorf("json: pointer %q has invalid escape at offset %d", ptr, i)
+		}
+	}
+	return nil
+}
+
+// parsePointerIndex parses array index without leading zeros,
+// too big index is limited as it is not found anyway
+func parsePointerIndex(token string) (int, bool) {
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(token); i++ {
+		c := token[i]
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		if n < math.MaxInt32 {
+			n = n*10 + int(c-'0')
+		}
+	}
+	return n, true
+}
+
+// pointerTokenEqual compares key with pointer token decoding ~0 and ~1 escapes
+func pointerTokenEqual(key []byte, token string) bool {
+	for token != "" {
+		c := token[0]
+		token = token[1:]
+		if c == '~' {
+			if token[0] == '0' {
+				c = '~'
+			} else {
+				c = '/'
+			}
+			token = token[1:]
+		}
+		if len(key) == 0 || key[0] != c {
+			return false
+		}
+		key = key[1:]
+	}
+	return len(key) == 0
+}
@@ -0,0 +1,221 @@
+package jessy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+var errDeleteRoot = errors.New("json: whole value can not be deleted by empty pointer")
+
+var pointerTokenUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// SetBytes returns copy of data where value at JSON pointer ptr is replaced by value
+// encoded as Marshal does, without decoding of data. Missing member of object is added
+// with missing parent objects, "-" or index equal to length of array appends the element.
+// Values before the target are scanned and values after it are copied as is
+func SetBytes(data []byte, ptr string, value any) ([]byte, error) {
+	return SetBytesFlags(data, ptr, value, EncodeStandard)
+}
+
+// SetBytesFlags is like SetBytes but encodes value with flags
+func SetBytesFlags(data []byte, ptr string, value any, flags Flags) ([]byte, error) {
+	return editPointer(data, ptr, editSet, flags, func(dst []byte) ([]byte, error) {
+		n := len(dst)
+		dst, err := encodeAny(dst, value, flags)
+		if err == nil && len(dst) == n {
+			err = fmt.Errorf("json: value of type %T is encoded to nothing", value)
+		}
+		return dst, err
+	})
+}
+
+// pointerEdit is a way of editPointer to put value
+type pointerEdit uint8
+
+const (
+	editSet     pointerEdit = iota // replace value or add it with missing parents
+	editAdd                        // replace member or insert element into existing parent
+	editReplace                    // replace existing value
+)
+
+// editPointer returns copy of data with value written by write at pointer
+func editPointer(data []byte, ptr string, edit pointerEdit, flags Flags, write func(dst []byte) ([]byte, error)) ([]byte, error) {
+	if err := checkPointer(ptr); err != nil {
+		return nil, err
+	}
+	var it Iterator
+	it.Reset(data)
+
+	var prefix, suffix []byte // keys and braces of added members around value
+	for ptr != "" {
+		var token string
+		token, ptr = nextPointerToken(ptr)
+
+		var found bool
+		switch c := it.peek(); c {
+		case '{':
+			_, found = it.seekKey(token)
+		case '[':
+			index, ok := parsePointerIndex(token)
+			if !ok && token != "-" {
+				return nil, errPointerIndex(token)
+			}
+			if !ok {
+				index = math.MaxInt
+			}
+			count, ok := it.seekIndex(index)
+			if found = ok; !found && count != index && token != "-" && it.err == nil {
+				return nil, ErrPointerNotFound
+			}
+			if found && ptr == "" && edit == editAdd {
+				// the element is inserted before the found one
+				it.peek()
+				return spliceValue(data, it.pos, it.pos, nil, []byte{','}, write)
+			}
+		case 0:
+			it.failAt(c, "looking for beginning of value")
+		default:
+			return nil, ErrPointerNotFound
+		}
+		if it.err != nil {
+			return nil, it.err
+		}
+		if found {
+			continue
+		}
+		if edit == editReplace || (edit == editAdd && ptr != "") {
+			return nil, ErrPointerNotFound
+		}
+		if !pointerContainerEmpty(data, it.pos) {
+			prefix = append(prefix, ',')
+		}
+		if data[it.pos] == '}' {
+			prefix = appendPointerKey(prefix, token, flags)
+		}
+		for ptr != "" {
+			token, ptr = nextPointerToken(ptr)
+			prefix = appendPointerKey(append(prefix, '{'), token, flags)
+			suffix = append(suffix, '}')
+		}
+		return spliceValue(data, it.pos, it.pos, prefix, suffix, write)
+	}
+
+	if c := it.peek(); c == 0 {
+		it.failAt(c, "looking for beginning of value")
+	}
+	start := it.pos
+	it.skip(0)
+	if it.err != nil {
+		return nil, it.err
+	}
+	return spliceValue(data, start, it.pos, nil, nil, write)
+}
+
+// DeleteBytes returns copy of data without value at JSON pointer ptr
+// with its key and comma, ErrPointerNotFound is returned if there is no value
+func DeleteBytes(data []byte, ptr string) ([]byte, error) {
+	if err := checkPointer(ptr); err != nil {
+		return nil, err
+	}
+	if ptr == "" {
+		return nil, errDeleteRoot
+	}
+	i := strings.LastIndexByte(ptr, '/')
+	parent, token := ptr[:i], ptr[i+1:]
+
+	var it Iterator
+	it.Reset(data)
+	if err := it.seekPointer(parent); err != nil {
+		return nil, err
+	}
+	var start int
+	var found bool
+	container := it.peek()
+	switch c := container; c {
+	case '{':
+		start, found = it.seekKey(token)
+	case '[':
+		index, ok := parsePointerIndex(token)
+		if !ok && token != "-" {
+			return nil, errPointerIndex(token)
+		}
+		if ok {
+			_, found = it.seekIndex(index)
+			it.peek()
+			start = it.pos
+		}
+	case 0:
+		it.failAt(c, "looking for beginning of value")
+	}
+	if it.err != nil {
+		return nil, it.err
+	}
+	if !found {
+		return nil, ErrPointerNotFound
+	}
+	it.skip(0)
+	end := it.pos
+	switch c := it.peek(); {
+	case c == ',':
+		it.pos++
+		it.peek()
+		end = it.pos
+	case c == '}' && container == '{', c == ']' && container == '[':
+		if prev := pointerPrevByte(data, start); prev >= 0 && data[prev] == ',' {
+			start = prev
+		}
+	case container == '{':
+		it.failAt(c, "after object key:value pair")
+	default:
+		it.failAt(c, "after array element")
+	}
+	if it.err != nil {
+		return nil, it.err
+	}
+	dst := make([]byte, 0, len(data)-(end-start))
+	dst = append(dst, data[:start]...)
+	return append(dst, data[end:]...), nil
+}
+
+// spliceValue returns data with bytes from start to end replaced by value
+// written by write between prefix and suffix
+func spliceValue(data []byte, start, end int, prefix, suffix []byte, write func(dst []byte) ([]byte, error)) ([]byte, error) {
+	dst := make([]byte, 0, len(data)-(end-start)+len(prefix)+len(suffix)+64)
+	dst = append(dst, data[:start]...)
+	dst = append(dst, prefix...)
+	dst, err := write(dst)
+	if err != nil {
+		return nil, err
+	}
+	dst = append(dst, suffix...)
+	return append(dst, data[end:]...), nil
+}
+
+// appendPointerKey appends unescaped pointer token as object key with colon
+func appendPointerKey(dst []byte, token string, flags Flags) []byte {
+	key := pointerTokenUnescaper.Replace(token)
+	dst = zstr.AppendQuotedString(dst, []byte(key), flags.Has(EscapeHTML))
+	return append(dst, ':')
+}
+
+// pointerContainerEmpty reports that closing bracket at pos follows opening one
+func pointerContainerEmpty(data []byte, pos int) bool {
+	prev := pointerPrevByte(data, pos)
+	return data[prev] == '{' || data[prev] == '['
+}
+
+// pointerPrevByte returns offset of the last non-space byte before pos or -1
+func pointerPrevByte(data []byte, pos int) int {
+	for pos--; pos >= 0; pos-- {
+		switch data[pos] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return pos
+		}
+	}
+	return pos
+}
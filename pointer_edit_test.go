@@ -0,0 +1,100 @@
+package jessy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestSetBytes(t *testing.T) {
+	data := []byte(`{"a": {"b": [1, 2, {"c": "x"}], "e": {}}, "f": [], "g": 1, "a/b": 2}`)
+	cases := []struct {
+		ptr    string
+		value  any
+		result string
+	}{
+		{"", "v", `"v"`},
+		{"/g", map[string]int{"z": 1, "y": 2}, `{"a": {"b": [1, 2, {"c": "x"}], "e": {}}, "f": [], "g": {"y":2,"z":1}, "a/b": 2}`},
+		{"/a/b/2/c", "y", `{"a": {"b": [1, 2, {"c": "y"}], "e": {}}, "f": [], "g": 1, "a/b": 2}`},
+		{"/a/b/1", nil, `{"a": {"b": [1, null, {"c": "x"}], "e": {}}, "f": [], "g": 1, "a/b": 2}`},
+		{"/a~1b", []int{1}, `{"a": {"b": [1, 2, {"c": "x"}], "e": {}}, "f": [], "g": 1, "a/b": [1]}`},
+		{"/h", true, `{"a": {"b": [1, 2, {"c": "x"}], "e": {}}, "f": [], "g": 1, "a/b": 2,"h":true}`},
+		{"/a/e/i", 1.5, `{"a": {"b": [1, 2, {"c": "x"}], "e": {"i":1.5}}, "f": [], "g": 1, "a/b": 2}`},
+		{"/a/e/j/k~0/l", 1, `{"a": {"b": [1, 2, {"c": "x"}], "e": {"j":{"k~":{"l":1}}}}, "f": [], "g": 1, "a/b": 2}`},
+		{"/a/b/-", 3, `{"a": {"b": [1, 2, {"c": "x"},3], "e": {}}, "f": [], "g": 1, "a/b": 2}`},
+		{"/a/b/3", 3, `{"a": {"b": [1, 2, {"c": "x"},3], "e": {}}, "f": [], "g": 1, "a/b": 2}`},
+		{"/f/0", "x", `{"a": {"b": [1, 2, {"c": "x"}], "e": {}}, "f": ["x"], "g": 1, "a/b": 2}`},
+		{"/f/-/m", "x", `{"a": {"b": [1, 2, {"c": "x"}], "e": {}}, "f": [{"m":"x"}], "g": 1, "a/b": 2}`},
+	}
+	for _, c := range cases {
+		result, err := SetBytes(data, c.ptr, c.value)
+		require.NoError(t, err)
+		require.Equal(t, c.result, string(result))
+		require.Equal(t, true, Valid(result))
+	}
+	result, err := SetBytesFlags([]byte(` {"a": 1} `), "/a", "<", EncodeStandard.Exclude(EscapeHTML))
+	require.NoError(t, err)
+	require.Equal(t, ` {"a": "<"} `, string(result))
+
+	// values after the target are copied as is
+	result, err = SetBytes([]byte(`{"a": 1, "b": [1, 2`), "/a", 2)
+	require.NoError(t, err)
+	require.Equal(t, `{"a": 2, "b": [1, 2`, string(result))
+
+	for _, ptr := range []string{"/a/b/4", "/g/x", "/a/b/2/c/d"} {
+		_, err = SetBytes(data, ptr, 1)
+		require.Equal(t, ErrPointerNotFound, err)
+	}
+	for _, ptr := range []string{"a", "/a/b/x", "/a/b/01"} {
+		_, err = SetBytes(data, ptr, 1)
+		if err == nil || errors.Is(err, ErrPointerNotFound) {
+			t.Fatalf("%q: expected pointer error, actual %v", ptr, err)
+		}
+	}
+	_, err = SetBytes(data, "/g", func() {})
+	require.NotEqual(t, nil, err)
+	_, err = SetBytes([]byte(`{"a": [1 2]}`), "/a/-", 1)
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected syntax error, actual %v", err)
+	}
+}
+
+func TestDeleteBytes(t *testing.T) {
+	data := []byte(`{"a": 1, "b": [1, 2, 3], "c": {"d": true} }`)
+	cases := []struct {
+		ptr    string
+		result string
+	}{
+		{"/a", `{"b": [1, 2, 3], "c": {"d": true} }`},
+		{"/b", `{"a": 1, "c": {"d": true} }`},
+		{"/c", `{"a": 1, "b": [1, 2, 3] }`},
+		{"/c/d", `{"a": 1, "b": [1, 2, 3], "c": {} }`},
+		{"/b/0", `{"a": 1, "b": [2, 3], "c": {"d": true} }`},
+		{"/b/2", `{"a": 1, "b": [1, 2], "c": {"d": true} }`},
+	}
+	for _, c := range cases {
+		result, err := DeleteBytes(data, c.ptr)
+		require.NoError(t, err)
+		require.Equal(t, c.result, string(result))
+	}
+	for _, ptr := range []string{"/x", "/b/3", "/b/-", "/a/x", "/x/y"} {
+		_, err := DeleteBytes(data, ptr)
+		require.Equal(t, ErrPointerNotFound, err)
+	}
+	for _, ptr := range []string{"", "b", "/b/x"} {
+		_, err := DeleteBytes(data, ptr)
+		if err == nil || errors.Is(err, ErrPointerNotFound) {
+			t.Fatalf("%q: expected pointer error, actual %v", ptr, err)
+		}
+	}
+	// value must be followed by comma or end of its container
+	for _, c := range [][2]string{{`{"a":1 "b":2}`, "/a"}, {`{"a":1`, "/a"}, {`[1 2]`, "/0"}, {`[1}`, "/0"}, {`{"a":1]`, "/a"}} {
+		_, err := DeleteBytes([]byte(c[0]), c[1])
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%s %s: expected syntax error, actual %v", c[0], c[1], err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package jessy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestGetPointer(t *testing.T) {
+	data := []byte(` {
+		"data": {"items": [{"id": 1}, {"id": 2, "skip": [1, {"x": "]"}]}, {"id": 3, "name": "cé"}]},
+		"a/b": 1, "m~n": 2, "k\"ey": true, "": {"": 1.5},
+		"big": 18446744073709551615
+	}`)
+	cases := []struct {
+		ptr string
+		raw string
+	}{
+		{"/data/items/2/id", `3`},
+		{"/data/items/1", `{"id": 2, "skip": [1, {"x": "]"}]}`},
+		{"/data/items/1/skip/1/x", `"]"`},
+		{"/a~1b", `1`},
+		{"/m~0n", `2`},
+		{"/k\"ey", `true`},
+		{"//", `1.5`},
+		{"/big", `18446744073709551615`},
+	}
+	for _, c := range cases {
+		raw, err := GetPointer(data, c.ptr)
+		require.NoError(t, err)
+		require.Equal(t, c.raw, string(raw))
+	}
+	raw, err := GetPointer([]byte(` [1] `), "")
+	require.NoError(t, err)
+	require.Equal(t, `[1]`, string(raw))
+
+	for _, ptr := range []string{"/missing", "/data/items/3", "/data/items/-", "/data/items/99999999999", "/a~1b/x", "/data/x"} {
+		_, err = GetPointer(data, ptr)
+		require.Equal(t, ErrPointerNotFound, err)
+	}
+	for _, ptr := range []string{"data", "/m~2n", "/m~", "/data/items/01", "/data/items/x"} {
+		_, err = GetPointer(data, ptr)
+		if err == nil || errors.Is(err, ErrPointerNotFound) {
+			t.Fatalf("%q: expected pointer error, actual %v", ptr, err)
+		}
+	}
+	// the rest of document is not read after the target, invalid parts before it are errors
+	raw, err = GetPointer([]byte(`{"a": 1, "b": [1, 2`), "/a")
+	require.NoError(t, err)
+	require.Equal(t, `1`, string(raw))
+	_, err = GetPointer([]byte(`{"a": [1, 2 "b": 1}`), "/b")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected syntax error, actual %v", err)
+	}
+
+	s, err := GetString(data, "/data/items/2/name")
+	require.NoError(t, err)
+	require.Equal(t, "cé", s)
+	i, err := GetInt64(data, "/data/items/0/id")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), i)
+	u, err := GetUint64(data, "/big")
+	require.NoError(t, err)
+	require.Equal(t, uint64(18446744073709551615), u)
+	f, err := GetFloat64(data, "//")
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+	b, err := GetBool(data, "/k\"ey")
+	require.NoError(t, err)
+	require.Equal(t, true, b)
+	_, err = GetInt64(data, "/data")
+	require.NotEqual(t, nil, err)
+
+	var item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	require.NoError(t, UnmarshalAt(data, "/data/items/2", &item))
+	require.Equal(t, 3, item.ID)
+	require.Equal(t, "cé", item.Name)
+	require.Equal(t, ErrPointerNotFound, UnmarshalAt(data, "/none", &item))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		GetInt64(data, "/data/items/2/id")
+		GetPointer(data, "/data/items/1/skip")
+	})
+	require.Equal(t, 0.0, allocs)
+}
@@ -0,0 +1,44 @@
+package std
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+)
+
+// unmarshalBigNumber decodes number literal into big.Float or big.Rat,
+// ok is false for other types
+func unmarshalBigNumber(ut encoding.TextUnmarshaler, s []byte) (ok bool, err error) {
+	switch v := ut.(type) {
+	case *big.Float:
+		if v.Prec() == 0 {
+			v.SetPrec(BigFloatPrec(s))
+		}
+		if _, _, err = v.Parse(string(s), 10); err != nil {
+			err = fmt.Errorf("json: cannot unmarshal %q into big.Float: %w", s, err)
+		}
+		return true, err
+	case *big.Rat:
+		if _, ok := v.SetString(string(s)); !ok {
+			err = fmt.Errorf("json: cannot unmarshal %q into big.Rat", s)
+		}
+		return true, err
+	}
+	return false, nil
+}
+
+// BigFloatPrec returns mantissa precision enough to keep
+// all decimal digits of the number literal (at least 64 bits)
+func BigFloatPrec(s []byte) uint {
+	digits := 0
+	for _, c := range s {
+		if c == 'e' || c == 'E' {
+			break
+		}
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	// log2(10) < 3.33 bits per decimal digit
+	return max(64, uint(digits)*10/3+1)
+}
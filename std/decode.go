@@ -9,7 +9,6 @@ package std
 
 import (
 	"encoding"
-	"encoding/base64"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -104,6 +103,32 @@ func Unmarshal(data []byte, v any) error {
 	return d.unmarshal(v)
 }
 
+// UnmarshalOptions is Unmarshal of struct field value with jessy json tag options, e.g. "bytes=hex"
+func UnmarshalOptions(data []byte, v any, options string) error {
+	var d decodeState
+	err := checkValid(data, &d.scan)
+	if err != nil {
+		return err
+	}
+
+	d.init(data)
+	d.opts = parseFieldOptions("," + options)
+	return d.unmarshal(v)
+}
+
+// UnmarshalOrdered is Unmarshal decoding objects into interface values by NewOrderedObject
+func UnmarshalOrdered(data []byte, v any) error {
+	var d decodeState
+	err := checkValid(data, &d.scan)
+	if err != nil {
+		return err
+	}
+
+	d.init(data)
+	d.orderedObjects = NewOrderedObject != nil
+	return d.unmarshal(v)
+}
+
 func UnmarshalTrusted(data []byte, v any) error {
 	// Check for well-formedness.
 	// Avoids filling out half a data structure
@@ -222,6 +247,8 @@ type decodeState struct {
 	savedError            error
 	useNumber             bool
 	disallowUnknownFields bool
+	opts                  fieldOptions // jessy options of the field being decoded
+	orderedObjects        bool         // objects of interface values are made by NewOrderedObject
 }
 
 // readIndex returns the position of the last byte read.
@@ -238,6 +265,7 @@ func (d *decodeState) init(data []byte) *decodeState {
 	d.data = data
 	d.off = 0
 	d.savedError = nil
+	d.opts = 0
 	if d.errorContext != nil {
 		d.errorContext.Struct = nil
 		// Reuse the allocated space for the FieldStack slice.
@@ -624,6 +652,8 @@ func (d *decodeState) object(v reflect.Value) error {
 	}
 
 	var fields structFields
+	var fieldsOpts map[string]fieldOptions
+	origOpts := d.opts
 
 	// Check type of target:
 	//   struct or
@@ -649,6 +679,7 @@ func (d *decodeState) object(v reflect.Value) error {
 		}
 	case reflect.Struct:
 		fields = cachedTypeFields(t)
+		fieldsOpts = cachedFieldOptions(t, fields)
 		// ok
 	default:
 		d.saveError(&UnmarshalTypeError{Value: "object", Type: t, Offset: int64(d.off)})
@@ -699,9 +730,11 @@ func (d *decodeState) object(v reflect.Value) error {
 			if f == nil {
 				f = fields.byFoldedName[string(foldName(key))]
 			}
+			d.opts = 0
 			if f != nil {
 				subv = v
 				destring = f.quoted
+				d.opts = fieldsOpts[f.name]
 				for _, i := range f.index {
 					if subv.Kind() == reflect.Pointer {
 						if subv.IsNil() {
@@ -821,6 +854,7 @@ func (d *decodeState) object(v reflect.Value) error {
 			panic(phasePanicMsg)
 		}
 	}
+	d.opts = origOpts
 	return nil
 }
 
@@ -854,9 +888,21 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 	isNull := item[0] == 'n' // null
 	u, ut, pv := indirect(v, isNull)
 	if u != nil {
+		if item[0] == '"' && d.opts.has(optQuotedInt) {
+			// quoted big integers, e.g. big.Int
+			if s, ok := unquoteBytes(item); ok && isIntLiteral(s) {
+				return u.UnmarshalJSON(s)
+			}
+		}
 		return u.UnmarshalJSON(item)
 	}
 	if ut != nil {
+		if item[0] != '"' && item[0] != 'n' && item[0] != 't' && item[0] != 'f' {
+			// big.Float and big.Rat are decoded from numbers without float64 rounding
+			if ok, err := unmarshalBigNumber(ut, item); ok {
+				return err
+			}
+		}
 		if item[0] != '"' {
 			if fromQuoted {
 				d.saveError(fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into %v", item, v.Type()))
@@ -879,6 +925,9 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 			}
 			panic(phasePanicMsg)
 		}
+		if ok, err := unmarshalBigNumber(ut, s); ok {
+			return err
+		}
 		return ut.UnmarshalText(s)
 	}
 
@@ -933,18 +982,39 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 		switch v.Kind() {
 		default:
 			d.saveError(&UnmarshalTypeError{Value: "string", Type: v.Type(), Offset: int64(d.readIndex())})
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if !d.opts.has(optQuotedInt) || fromQuoted || !isIntLiteral(s) {
+				d.saveError(&UnmarshalTypeError{Value: "string", Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			return d.literalStore(s, v, true)
 		case reflect.Slice:
 			if v.Type().Elem().Kind() != reflect.Uint8 {
 				d.saveError(&UnmarshalTypeError{Value: "string", Type: v.Type(), Offset: int64(d.readIndex())})
 				break
 			}
-			b := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
-			n, err := base64.StdEncoding.Decode(b, s)
+			b, err := decodeBytes(d.opts, s)
 			if err != nil {
 				d.saveError(err)
 				break
 			}
-			v.SetBytes(b[:n])
+			v.SetBytes(b)
+		case reflect.Array:
+			if v.Type().Elem().Kind() != reflect.Uint8 || !d.opts.has(optBytesFormat) {
+				d.saveError(&UnmarshalTypeError{Value: "string", Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			b, err := decodeBytes(d.opts, s)
+			if err != nil {
+				d.saveError(err)
+				break
+			}
+			if len(b) != v.Len() {
+				d.saveError(&UnmarshalTypeError{Value: "string of " + strconv.Itoa(len(b)) + " bytes", Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			reflect.Copy(v, reflect.ValueOf(b))
 		case reflect.String:
 			t := string(s)
 			if v.Type() == numberType && !isValidNumber(t) {
@@ -1065,9 +1135,15 @@ func (d *decodeState) arrayInterface() []any {
 	return v
 }
 
-// objectInterface is like object but returns map[string]interface{}.
-func (d *decodeState) objectInterface() map[string]any {
-	m := make(map[string]any)
+// objectInterface is like object but returns map[string]interface{}
+// or value of NewOrderedObject if objects are decoded in order.
+func (d *decodeState) objectInterface() any {
+	var m map[string]any
+	var keys []string
+	var values []any
+	if !d.orderedObjects {
+		m = make(map[string]any)
+	}
 	for {
 		// Read opening " of string key or closing }.
 		d.scanWhile(scanSkipSpace)
@@ -1098,7 +1174,12 @@ func (d *decodeState) objectInterface() map[string]any {
 		d.scanWhile(scanSkipSpace)
 
 		// Read value.
-		m[key] = d.valueInterface()
+		if d.orderedObjects {
+			keys = append(keys, key)
+			values = append(values, d.valueInterface())
+		} else {
+			m[key] = d.valueInterface()
+		}
 
 		// Next token must be , or }.
 		if d.opcode == scanSkipSpace {
@@ -1111,6 +1192,9 @@ func (d *decodeState) objectInterface() map[string]any {
 			panic(phasePanicMsg)
 		}
 	}
+	if d.orderedObjects {
+		return NewOrderedObject(keys, values)
+	}
 	return m
 }
 
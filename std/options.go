@@ -0,0 +1,121 @@
+package std
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldOptions are jessy specific struct tag options
+// which are not known to encoding/json but affect decoding
+type fieldOptions uint32
+
+const (
+	// `json:",int64=string"` and `json:",int64=safe"`
+	// integers are accepted both quoted and unquoted
+	optQuotedInt fieldOptions = 1 << iota
+
+	// `json:",bytes=..."` string formats of []byte and [N]byte
+	optBytesBase64
+	optBytesBase64URL
+	optBytesBase64Raw
+	optBytesHex
+
+	optBytesFormat = optBytesBase64 | optBytesBase64URL | optBytesBase64Raw | optBytesHex
+)
+
+func (o fieldOptions) has(opt fieldOptions) bool {
+	return o&opt != 0
+}
+
+func parseFieldOptions(tag string) (opts fieldOptions) {
+	_, tag, _ = strings.Cut(tag, ",")
+	for tag != "" {
+		var option string
+		option, tag, _ = strings.Cut(tag, ",")
+		name, value, _ := strings.Cut(option, "=")
+		switch name {
+		case "int64":
+			if value == "string" || value == "safe" {
+				opts |= optQuotedInt
+			}
+		case "bytes":
+			switch value {
+			case "base64":
+				opts |= optBytesBase64
+			case "base64url":
+				opts |= optBytesBase64URL
+			case "base64raw":
+				opts |= optBytesBase64Raw
+			case "hex":
+				opts |= optBytesHex
+			}
+		}
+	}
+	return
+}
+
+var fieldOptionsCache sync.Map // map[reflect.Type]map[string]fieldOptions
+
+// cachedFieldOptions returns jessy options of struct fields by field name,
+// map is nil if struct has no fields with such options
+func cachedFieldOptions(t reflect.Type, fields structFields) map[string]fieldOptions {
+	if f, ok := fieldOptionsCache.Load(t); ok {
+		return f.(map[string]fieldOptions)
+	}
+	var options map[string]fieldOptions
+	for i := range fields.List {
+		f := &fields.List[i]
+		opts := parseFieldOptions(t.FieldByIndex(f.index).Tag.Get("json"))
+		if opts == 0 {
+			continue
+		}
+		if options == nil {
+			options = make(map[string]fieldOptions)
+		}
+		options[f.name] = opts
+	}
+	f, _ := fieldOptionsCache.LoadOrStore(t, options)
+	return f.(map[string]fieldOptions)
+}
+
+// isIntLiteral reports whether s is an integer number literal
+func isIntLiteral(s []byte) bool {
+	if len(s) != 0 && s[0] == '-' {
+		s = s[1:]
+	}
+	if len(s) == 0 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeBytes decodes string of []byte or [N]byte by field bytes format,
+// standard base64 by default
+func decodeBytes(opts fieldOptions, s []byte) ([]byte, error) {
+	if opts.has(optBytesHex) {
+		if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+			s = s[2:]
+		}
+		b := make([]byte, hex.DecodedLen(len(s)))
+		n, err := hex.Decode(b, s)
+		return b[:n], err
+	}
+	enc := base64.StdEncoding
+	switch {
+	case opts.has(optBytesBase64URL):
+		enc = base64.URLEncoding
+	case opts.has(optBytesBase64Raw):
+		enc = base64.RawStdEncoding
+	}
+	b := make([]byte, enc.DecodedLen(len(s)))
+	n, err := enc.Decode(b, s)
+	return b[:n], err
+}
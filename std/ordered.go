@@ -0,0 +1,6 @@
+package std
+
+// NewOrderedObject returns value of JSON object of interface value decoded
+// by UnmarshalOrdered from keys and values of its members in order,
+// it is set by jessy to make its OrderedMap
+var NewOrderedObject func(keys []string, values []any) any
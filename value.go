@@ -0,0 +1,516 @@
+package jessy
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/avpetkun/jessy-go/zgo"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+// Value is a mutable JSON document tree: null, bool, number, string, array
+// or object with members in original order. Numbers keep their literals,
+// so no precision is lost. The zero Value is null, methods reading values
+// are safe on nil Value for chains like v.Get("a").Index(0).Get("b")
+type Value struct {
+	kind    Kind
+	s       string        // string, literal of number, bool and null
+	items   []*Value      // elements of array
+	members []valueMember // members of object
+}
+
+type valueMember struct {
+	key   string
+	value *Value
+}
+
+// Arena allocates values, slices and strings of parsed documents by chunks,
+// so a document costs few allocations. Reset reuses the memory: values
+// of documents parsed before it and their strings must not be used after
+type Arena struct {
+	values  []Value
+	items   []*Value
+	members []valueMember
+	buf     []byte
+
+	// elements and members of open containers of Parse
+	openItems   []*Value
+	openMembers []valueMember
+}
+
+// arenaAlloc returns n items of chunk, full chunk is replaced by a bigger one
+// and stays alive while its items are used
+func arenaAlloc[T any](chunk *[]T, n int) []T {
+	c := *chunk
+	if cap(c)-len(c) < n {
+		c = make([]T, 0, max(2*cap(c), n, 64))
+	}
+	*chunk = c[:len(c)+n]
+	return c[len(c) : len(c)+n : len(c)+n]
+}
+
+// Reset frees all values of arena for reuse
+func (a *Arena) Reset() {
+	a.values = a.values[:0]
+	a.items = a.items[:0]
+	a.members = a.members[:0]
+	a.buf = a.buf[:0]
+}
+
+func (a *Arena) value(kind Kind, s string) *Value {
+	v := &arenaAlloc(&a.values, 1)[0]
+	*v = Value{kind: kind, s: s}
+	return v
+}
+
+func (a *Arena) str(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	s := arenaAlloc(&a.buf, len(b))
+	copy(s, b)
+	return zgo.B2S(s)
+}
+
+// NewNull returns null value
+func (a *Arena) NewNull() *Value { return a.value(KindNull, "null") }
+
+// NewBool returns true or false value
+func (a *Arena) NewBool(b bool) *Value {
+	if b {
+		return a.value(KindBool, "true")
+	}
+	return a.value(KindBool, "false")
+}
+
+// NewInt64 returns number value of n
+func (a *Arena) NewInt64(n int64) *Value {
+	return a.value(KindNumber, a.str(zstr.AppendInt64(nil, n)))
+}
+
+// NewString returns string value of s
+func (a *Arena) NewString(s string) *Value { return a.value(KindString, s) }
+
+// NewObject returns empty object
+func (a *Arena) NewObject() *Value { return a.value(KindObject, "") }
+
+// NewArray returns empty array
+func (a *Arena) NewArray() *Value { return a.value(KindArray, "") }
+
+// NewValue returns value of x encoded by Marshal
+func (a *Arena) NewValue(x any) (*Value, error) {
+	data, err := Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	return a.Parse(data)
+}
+
+// Parse returns value of one JSON document in data allocated by arena,
+// strings are copied, so data may be changed after
+func (a *Arena) Parse(data []byte) (*Value, error) {
+	var it Iterator
+	it.Reset(data)
+	v := a.parse(&it, 0)
+	if err := it.End(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ParseValue returns value of one JSON document in data allocated by a new Arena
+func ParseValue(data []byte) (*Value, error) {
+	return new(Arena).Parse(data)
+}
+
+func (a *Arena) parse(it *Iterator, depth int) *Value {
+	switch c := it.peek(); c {
+	case '{', '[':
+		if depth == iteratorMaxDepth {
+			it.fail("exceeded max depth")
+			return nil
+		}
+		if c == '{' {
+			v := a.value(KindObject, "")
+			start := len(a.openMembers)
+			it.ReadObject(func(key []byte) bool {
+				k := a.str(key)
+				a.openMembers = append(a.openMembers, valueMember{k, a.parse(it, depth+1)})
+				return it.err == nil
+			})
+			if n := len(a.openMembers) - start; n != 0 {
+				v.members = arenaAlloc(&a.members, n)
+				copy(v.members, a.openMembers[start:])
+			}
+			clear(a.openMembers[start:])
+			a.openMembers = a.openMembers[:start]
+			return v
+		}
+		v := a.value(KindArray, "")
+		start := len(a.openItems)
+		it.ReadArray(func() bool {
+			a.openItems = append(a.openItems, a.parse(it, depth+1))
+			return it.err == nil
+		})
+		if n := len(a.openItems) - start; n != 0 {
+			v.items = arenaAlloc(&a.items, n)
+			copy(v.items, a.openItems[start:])
+		}
+		clear(a.openItems[start:])
+		a.openItems = a.openItems[:start]
+		return v
+	case '"':
+		return a.value(KindString, a.str(it.ReadStringBytes()))
+	case 't', 'f':
+		return a.NewBool(it.ReadBool())
+	case 'n':
+		it.ReadNull()
+		return a.NewNull()
+	}
+	return a.value(KindNumber, a.str(it.ReadNumber()))
+}
+
+// UnmarshalJSON sets v to parsed data allocated by a new Arena
+func (v *Value) UnmarshalJSON(data []byte) error {
+	p, err := ParseValue(data)
+	if err != nil {
+		return err
+	}
+	*v = *p
+	return nil
+}
+
+// AppendJSON appends compact JSON of v, strings are escaped as by Marshal
+func (v *Value) AppendJSON(dst []byte) ([]byte, error) {
+	switch v.Kind() {
+	case KindObject:
+		dst = append(dst, '{')
+		for i, m := range v.members {
+			if i != 0 {
+				dst = append(dst, ',')
+			}
+			dst = zstr.AppendQuotedString(dst, zgo.S2B(m.key), true)
+			dst = append(dst, ':')
+			dst, _ = m.value.AppendJSON(dst)
+		}
+		return append(dst, '}'), nil
+	case KindArray:
+		dst = append(dst, '[')
+		for i, item := range v.items {
+			if i != 0 {
+				dst = append(dst, ',')
+			}
+			dst, _ = item.AppendJSON(dst)
+		}
+		return append(dst, ']'), nil
+	case KindString:
+		return zstr.AppendQuotedString(dst, zgo.S2B(v.s), true), nil
+	case KindNull, KindInvalid:
+		return append(dst, 'n', 'u', 'l', 'l'), nil
+	}
+	return append(dst, v.s...), nil
+}
+
+// MarshalJSON returns compact JSON of v
+func (v *Value) MarshalJSON() ([]byte, error) {
+	return v.AppendJSON(nil)
+}
+
+// String returns compact JSON of v
+func (v *Value) String() string {
+	data, _ := v.AppendJSON(nil)
+	return string(data)
+}
+
+// Kind returns kind of v, KindInvalid for nil Value
+func (v *Value) Kind() Kind {
+	if v == nil {
+		return KindInvalid
+	}
+	if v.kind == KindInvalid {
+		return KindNull
+	}
+	return v.kind
+}
+
+// Len returns count of elements of array or members of object
+func (v *Value) Len() int {
+	switch v.Kind() {
+	case KindArray:
+		return len(v.items)
+	case KindObject:
+		return len(v.members)
+	}
+	return 0
+}
+
+// Get returns value of key of object, the last one of duplicate keys
+// as Unmarshal keeps it, nil is returned for missing key or other kinds
+func (v *Value) Get(key string) *Value {
+	if i := v.find(key); i >= 0 {
+		return v.members[i].value
+	}
+	return nil
+}
+
+func (v *Value) find(key string) int {
+	if v.Kind() == KindObject {
+		for i := len(v.members) - 1; i >= 0; i-- {
+			if v.members[i].key == key {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Index returns element i of array, nil is returned for missing index or other kinds
+func (v *Value) Index(i int) *Value {
+	if v.Kind() == KindArray && 0 <= i && i < len(v.items) {
+		return v.items[i]
+	}
+	return nil
+}
+
+// Members calls f for members of object in order until f returns false
+func (v *Value) Members(f func(key string, value *Value) bool) {
+	if v.Kind() == KindObject {
+		for _, m := range v.members {
+			if !f(m.key, m.value) {
+				return
+			}
+		}
+	}
+}
+
+// Elements calls f for elements of array until f returns false
+func (v *Value) Elements(f func(i int, value *Value) bool) {
+	if v.Kind() == KindArray {
+		for i, item := range v.items {
+			if !f(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// Set sets value of key of object replacing the existing one or adding it to the end,
+// nil x is null. Values of other kinds are not changed
+func (v *Value) Set(key string, x *Value) {
+	if v.Kind() != KindObject {
+		return
+	}
+	if i := v.find(key); i >= 0 {
+		v.members[i].value = x
+	} else {
+		v.members = append(v.members, valueMember{key, x})
+	}
+}
+
+// Delete removes all members of key from object and reports whether there were any
+func (v *Value) Delete(key string) bool {
+	if v.Kind() != KindObject {
+		return false
+	}
+	n := len(v.members)
+	v.members = slices.DeleteFunc(v.members, func(m valueMember) bool { return m.key == key })
+	return len(v.members) != n
+}
+
+// SetIndex replaces element i of array and reports whether it exists, nil x is null
+func (v *Value) SetIndex(i int, x *Value) bool {
+	if v.Kind() != KindArray || i < 0 || i >= len(v.items) {
+		return false
+	}
+	v.items[i] = x
+	return true
+}
+
+// Append adds x to the end of array, nil x is null. Values of other kinds are not changed
+func (v *Value) Append(x *Value) {
+	if v.Kind() == KindArray {
+		v.items = append(v.items, x)
+	}
+}
+
+// Pointer returns value at JSON pointer ptr (RFC 6901), "" is v itself
+func (v *Value) Pointer(ptr string) (*Value, error) {
+	if err := checkPointer(ptr); err != nil {
+		return nil, err
+	}
+	for ptr != "" {
+		var token string
+		token, ptr = nextPointerToken(ptr)
+		next, err := v.pointerChild(token)
+		if err != nil {
+			return nil, err
+		}
+		v = next
+	}
+	return v, nil
+}
+
+func (v *Value) pointerChild(token string) (*Value, error) {
+	switch v.Kind() {
+	case KindObject:
+		if i := v.find(pointerTokenUnescaper.Replace(token)); i >= 0 {
+			return v.members[i].value, nil
+		}
+	case KindArray:
+		index, ok := parsePointerIndex(token)
+		if !ok && token != "-" {
+			return nil, errPointerIndex(token)
+		}
+		if ok && index < len(v.items) {
+			return v.items[index], nil
+		}
+	}
+	return nil, ErrPointerNotFound
+}
+
+// SetPointer sets value at JSON pointer ptr, its parent must exist:
+// member of object is set by Set, element of array is replaced and
+// index of array length or "-" appends x. Empty pointer replaces v with x
+func (v *Value) SetPointer(ptr string, x *Value) error {
+	parent, token, err := v.pointerParent(ptr)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		if x == nil {
+			*v = Value{kind: KindNull, s: "null"}
+		} else {
+			*v = *x
+		}
+		return nil
+	}
+	switch parent.Kind() {
+	case KindObject:
+		parent.Set(pointerTokenUnescaper.Replace(token), x)
+		return nil
+	case KindArray:
+		index, ok := parsePointerIndex(token)
+		if !ok && token != "-" {
+			return errPointerIndex(token)
+		}
+		if !ok || index == len(parent.items) {
+			parent.Append(x)
+			return nil
+		}
+		if parent.SetIndex(index, x) {
+			return nil
+		}
+	}
+	return ErrPointerNotFound
+}
+
+// DeletePointer removes value at JSON pointer ptr from its parent
+func (v *Value) DeletePointer(ptr string) error {
+	parent, token, err := v.pointerParent(ptr)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return errDeleteRoot
+	}
+	switch parent.Kind() {
+	case KindObject:
+		if parent.Delete(pointerTokenUnescaper.Replace(token)) {
+			return nil
+		}
+	case KindArray:
+		index, ok := parsePointerIndex(token)
+		if !ok && token != "-" {
+			return errPointerIndex(token)
+		}
+		if ok && index < len(parent.items) {
+			parent.items = slices.Delete(parent.items, index, index+1)
+			return nil
+		}
+	}
+	return ErrPointerNotFound
+}
+
+// pointerParent returns parent of value at pointer and the last token,
+// nil parent is returned for empty pointer
+func (v *Value) pointerParent(ptr string) (*Value, string, error) {
+	if err := checkPointer(ptr); err != nil {
+		return nil, "", err
+	}
+	if ptr == "" {
+		return nil, "", nil
+	}
+	i := len(ptr) - 1
+	for ptr[i] != '/' {
+		i--
+	}
+	parent, err := v.Pointer(ptr[:i])
+	if err != nil {
+		return nil, "", err
+	}
+	return parent, ptr[i+1:], nil
+}
+
+func (v *Value) expect(kind Kind) error {
+	if k := v.Kind(); k != kind {
+		return fmt.Errorf("json: value of kind %s is not %s", k, kind)
+	}
+	return nil
+}
+
+// Bool returns value of bool
+func (v *Value) Bool() (bool, error) {
+	if err := v.expect(KindBool); err != nil {
+		return false, err
+	}
+	return v.s == "true", nil
+}
+
+// StringValue returns value of string
+func (v *Value) StringValue() (string, error) {
+	if err := v.expect(KindString); err != nil {
+		return "", err
+	}
+	return v.s, nil
+}
+
+// Number returns literal of number
+func (v *Value) Number() (Number, error) {
+	if err := v.expect(KindNumber); err != nil {
+		return "", err
+	}
+	return Number(v.s), nil
+}
+
+// Int64 returns number as integer, numbers with fraction or out of range are errors
+func (v *Value) Int64() (int64, error) {
+	if err := v.expect(KindNumber); err != nil {
+		return 0, err
+	}
+	var it Iterator
+	it.Reset(zgo.S2B(v.s))
+	n := it.ReadInt64()
+	return n, it.err
+}
+
+// Uint64 returns number as unsigned integer
+func (v *Value) Uint64() (uint64, error) {
+	if err := v.expect(KindNumber); err != nil {
+		return 0, err
+	}
+	var it Iterator
+	it.Reset(zgo.S2B(v.s))
+	n := it.ReadUint64()
+	return n, it.err
+}
+
+// Float64 returns number as float
+func (v *Value) Float64() (float64, error) {
+	if err := v.expect(KindNumber); err != nil {
+		return 0, err
+	}
+	var it Iterator
+	it.Reset(zgo.S2B(v.s))
+	f := it.ReadFloat64()
+	return f, it.err
+}
@@ -0,0 +1,120 @@
+package jessy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+func TestValueParse(t *testing.T) {
+	data := []byte(` {"z": 1, "a": [true, false, null, "x\né"], "big": 12345678901234567890.5e-3, "o": {}, "e": [], "a": 2} `)
+	v, err := ParseValue(data)
+	require.NoError(t, err)
+	copy(data, strings.Repeat(" ", len(data)))
+
+	require.Equal(t, KindObject, v.Kind())
+	require.Equal(t, 6, v.Len())
+	require.Equal(t, `{"z":1,"a":[true,false,null,"x\né"],"big":12345678901234567890.5e-3,"o":{},"e":[],"a":2}`, v.String())
+
+	var keys []string
+	v.Members(func(key string, value *Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	require.Equal(t, []string{"z", "a", "big", "o", "e", "a"}, keys)
+
+	n, err := v.Get("a").Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+	big, err := v.Get("big").Number()
+	require.NoError(t, err)
+	require.Equal(t, Number("12345678901234567890.5e-3"), big)
+	f, err := v.Get("z").Float64()
+	require.NoError(t, err)
+	require.Equal(t, 1.0, f)
+	_, err = v.Get("big").Int64()
+	require.NotEqual(t, nil, err)
+	_, err = v.Get("z").StringValue()
+	require.NotEqual(t, nil, err)
+
+	arr, err := v.Pointer("/a")
+	require.NoError(t, err)
+	require.Equal(t, KindNumber, arr.Kind())
+	s, err := v.Get("o").Get("x").Index(3).StringValue()
+	require.NotEqual(t, nil, err)
+	require.Equal(t, "", s)
+	require.Equal(t, KindInvalid, v.Get("missing").Index(0).Kind())
+
+	for _, bad := range []string{``, `{`, `[1,]`, `{"a" 1}`, `x`, `1 2`, `"\x"`, strings.Repeat("[", iteratorMaxDepth+1)} {
+		_, err = ParseValue([]byte(bad))
+		require.NotEqual(t, nil, err)
+	}
+}
+
+func TestValueEdit(t *testing.T) {
+	var a Arena
+	v, err := a.Parse([]byte(`{"users": [{"name": "ann"}, {"name": "bob"}], "a/b": {"~": 1}}`))
+	require.NoError(t, err)
+
+	users := v.Get("users")
+	users.Index(0).Set("name", a.NewString("<ann>"))
+	users.Index(1).Set("age", a.NewInt64(30))
+	users.Append(a.NewNull())
+	require.Equal(t, true, users.SetIndex(2, a.NewBool(true)))
+	require.Equal(t, false, users.SetIndex(3, nil))
+
+	x, err := a.NewValue(map[string]int{"k": 1})
+	require.NoError(t, err)
+	require.NoError(t, v.SetPointer("/a~1b/~0", x))
+	require.NoError(t, v.SetPointer("/users/-", a.NewArray()))
+	require.NoError(t, v.SetPointer("/users/4", a.NewObject()))
+	require.NoError(t, v.SetPointer("/new", nil))
+	require.Equal(t, true, errors.Is(v.SetPointer("/missing/x", nil), ErrPointerNotFound))
+	require.Equal(t, true, errors.Is(v.SetPointer("/users/9", nil), ErrPointerNotFound))
+	require.NotEqual(t, nil, v.SetPointer("/users/x", nil))
+	require.NotEqual(t, nil, v.SetPointer("users", nil))
+	require.Equal(t,
+		`{"users":[{"name":"\u003cann\u003e"},{"name":"bob","age":30},true,[],{}],"a/b":{"~":{"k":1}},"new":null}`,
+		v.String())
+
+	require.NoError(t, v.DeletePointer("/users/2"))
+	require.NoError(t, v.DeletePointer("/users/0/name"))
+	require.Equal(t, true, v.Delete("new"))
+	require.Equal(t, false, v.Delete("new"))
+	require.Equal(t, true, errors.Is(v.DeletePointer("/users/9"), ErrPointerNotFound))
+	require.NotEqual(t, nil, v.DeletePointer(""))
+	require.Equal(t, `{"users":[{},{"name":"bob","age":30},[],{}],"a/b":{"~":{"k":1}}}`, v.String())
+
+	require.NoError(t, v.SetPointer("", a.NewString("root")))
+	require.Equal(t, `"root"`, v.String())
+
+	// arena memory is reused after reset
+	a.Reset()
+	v, err = a.Parse([]byte(`[1, "s"]`))
+	require.NoError(t, err)
+	require.Equal(t, `[1,"s"]`, v.String())
+}
+
+type valueDoc struct {
+	ID    int    `json:"id"`
+	Data  Value  `json:"data"`
+	Extra *Value `json:"extra,omitempty"`
+}
+
+func TestValueMarshal(t *testing.T) {
+	var doc valueDoc
+	require.NoError(t, Unmarshal([]byte(`{"id": 1, "data": {"b": 1.50, "a": [1, {"c": null}]}}`), &doc))
+	require.Equal(t, `{"b":1.50,"a":[1,{"c":null}]}`, doc.Data.String())
+	require.Equal(t, (*Value)(nil), doc.Extra)
+
+	data, err := Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, `{"data":{"b":1.50,"a":[1,{"c":null}]},"id":1}`, string(data))
+
+	doc.Extra = &Value{}
+	data, err = Marshal(&doc)
+	require.NoError(t, err)
+	require.Equal(t, `{"data":{"b":1.50,"a":[1,{"c":null}]},"extra":null,"id":1}`, string(data))
+}
@@ -0,0 +1,377 @@
+package jessy
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"runtime"
+
+	"github.com/avpetkun/jessy-go/zgo"
+	"github.com/avpetkun/jessy-go/zstr"
+)
+
+var (
+	errWriterNotObject = errors.New("json: key or object end outside of object")
+	errWriterNotArray  = errors.New("json: array end outside of array")
+	errWriterNoKey     = errors.New("json: object value without key")
+	errWriterNoValue   = errors.New("json: key without value")
+	errWriterTopValue  = errors.New("json: more than one top level value")
+	errWriterUnclosed  = errors.New("json: array or object is not closed")
+	errWriterEmpty     = errors.New("json: no value is written")
+	errWriterEmptyRaw  = errors.New("json: empty raw value")
+	errWriterTooDeep   = errors.New("json: nesting is too deep")
+	errWriterFieldPtr  = errors.New("json: field value is not a pointer")
+	errWriterQuoted    = errors.New("json: quoted value is not a number, bool or string")
+)
+
+// writerMaxDeep is max nesting of Writer containers
+const writerMaxDeep = 256
+
+// Writer appends JSON tokens to a buffer managing commas, quotes and nesting.
+// The first misuse error is kept and next writes are ignored,
+// so it is checked once by Result, e.g. in AppendJSON:
+//
+//	w := jessy.NewWriter(dst)
+//	w.ObjectStart()
+//	w.Key("id")
+//	w.Int64(v.ID)
+//	w.ObjectEnd()
+//	return w.Result()
+type Writer struct {
+	buf   []byte
+	flags Flags
+	err   error
+
+	depth    int
+	objects  [writerMaxDeep / 64]uint64 // bit is set for object levels
+	comma    bool                       // value was written at current level
+	afterKey bool
+}
+
+// NewWriter returns Writer appending to dst with EncodeStandard flags
+func NewWriter(dst []byte) Writer {
+	return Writer{buf: dst, flags: EncodeStandard}
+}
+
+// NewWriterFlags returns Writer appending to dst, flags set escaping
+// of strings and format of floats, bytes and values of Value
+func NewWriterFlags(dst []byte, flags Flags) Writer {
+	return Writer{buf: dst, flags: flags.Exclude(PrettySpaces | Colorize)}
+}
+
+// Reset starts new value appended to dst
+func (w *Writer) Reset(dst []byte) {
+	*w = Writer{buf: dst, flags: w.flags}
+}
+
+// Result returns written JSON and the first error of writing
+func (w *Writer) Result() ([]byte, error) {
+	if w.err == nil {
+		if w.depth != 0 || w.afterKey {
+			w.err = errWriterUnclosed
+		} else if !w.comma {
+			w.err = errWriterEmpty
+		}
+	}
+	return w.buf, w.err
+}
+
+// Err returns the first error of writing
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) inObject() bool {
+	i := w.depth - 1
+	return w.objects[i/64]&(1<<(i%64)) != 0
+}
+
+// beginValue checks that value is expected here and appends comma before it
+func (w *Writer) beginValue() bool {
+	if w.err != nil {
+		return false
+	}
+	switch {
+	case w.depth == 0:
+		if w.comma {
+			w.err = errWriterTopValue
+			return false
+		}
+	case w.inObject():
+		if !w.afterKey {
+			w.err = errWriterNoKey
+			return false
+		}
+	case w.comma:
+		w.buf = append(w.buf, ',')
+	}
+	return true
+}
+
+func (w *Writer) endValue() {
+	w.comma = true
+	w.afterKey = false
+}
+
+func (w *Writer) start(object bool, c byte) {
+	if !w.beginValue() {
+		return
+	}
+	if w.depth == writerMaxDeep {
+		w.err = errWriterTooDeep
+		return
+	}
+	i := w.depth
+	if object {
+		w.objects[i/64] |= 1 << (i % 64)
+	} else {
+		w.objects[i/64] &^= 1 << (i % 64)
+	}
+	w.depth++
+	w.comma = false
+	w.afterKey = false
+	w.buf = append(w.buf, c)
+}
+
+func (w *Writer) end(object bool, c byte) {
+	if w.err != nil {
+		return
+	}
+	if w.depth == 0 || w.inObject() != object {
+		if object {
+			w.err = errWriterNotObject
+		} else {
+			w.err = errWriterNotArray
+		}
+		return
+	}
+	if w.afterKey {
+		w.err = errWriterNoValue
+		return
+	}
+	w.depth--
+	w.buf = append(w.buf, c)
+	w.endValue()
+}
+
+func (w *Writer) ObjectStart() { w.start(true, '{') }
+func (w *Writer) ObjectEnd()   { w.end(true, '}') }
+func (w *Writer) ArrayStart()  { w.start(false, '[') }
+func (w *Writer) ArrayEnd()    { w.end(false, ']') }
+
+// Key writes key of the next object field
+func (w *Writer) Key(key string) {
+	w.KeyBytes(zgo.S2B(key))
+}
+
+// KeyBytes writes key of the next object field
+func (w *Writer) KeyBytes(key []byte) {
+	if w.err != nil {
+		return
+	}
+	if w.depth == 0 || !w.inObject() {
+		w.err = errWriterNotObject
+		return
+	}
+	if w.afterKey {
+		w.err = errWriterNoValue
+		return
+	}
+	if w.comma {
+		w.buf = append(w.buf, ',')
+	}
+	w.buf = w.appendString(w.buf, key)
+	w.buf = append(w.buf, ':')
+	w.afterKey = true
+}
+
+func (w *Writer) appendString(dst, s []byte) []byte {
+	if w.flags.Has(escapeStringFlags) {
+		return zstr.AppendQuotedStringFlags(dst, s, w.flags.escapeFlags())
+	}
+	return zstr.AppendQuotedString(dst, s, w.flags.Has(EscapeHTML))
+}
+
+func (w *Writer) String(s string) {
+	w.StringBytes(zgo.S2B(s))
+}
+
+func (w *Writer) StringBytes(s []byte) {
+	if w.beginValue() {
+		w.buf = w.appendString(w.buf, s)
+		w.endValue()
+	}
+}
+
+// Bytes writes data as string in format of Bytes flags, base64 by default
+func (w *Writer) Bytes(data []byte) {
+	if w.beginValue() {
+		w.buf = getBytesAppender(w.flags)(w.buf, data)
+		w.endValue()
+	}
+}
+
+func (w *Writer) Int(v int) {
+	w.Int64(int64(v))
+}
+
+func (w *Writer) Int64(v int64) {
+	if w.beginValue() {
+		w.buf = zstr.AppendInt64(w.buf, v)
+		w.endValue()
+	}
+}
+
+func (w *Writer) Uint(v uint) {
+	w.Uint64(uint64(v))
+}
+
+func (w *Writer) Uint64(v uint64) {
+	if w.beginValue() {
+		w.buf = zstr.AppendUint64(w.buf, v)
+		w.endValue()
+	}
+}
+
+func (w *Writer) Float32(v float32) {
+	w.float(float64(v), 32)
+}
+
+func (w *Writer) Float64(v float64) {
+	w.float(v, 64)
+}
+
+func (w *Writer) float(v float64, bitSize int) {
+	if !w.beginValue() {
+		return
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		w.err = errFloatNum
+		return
+	}
+	switch {
+	case w.flags.Has(floatFormatFlags):
+		w.buf = appendFloatFormat(w.buf, v, bitSize, w.flags)
+	case bitSize == 32:
+		w.buf = appendFloat32(w.buf, v)
+	default:
+		w.buf = appendFloat64(w.buf, v)
+	}
+	w.endValue()
+}
+
+func (w *Writer) Bool(v bool) {
+	if w.beginValue() {
+		if v {
+			w.buf = append(w.buf, 't', 'r', 'u', 'e')
+		} else {
+			w.buf = append(w.buf, 'f', 'a', 'l', 's', 'e')
+		}
+		w.endValue()
+	}
+}
+
+func (w *Writer) Null() {
+	if w.beginValue() {
+		w.buf = append(w.buf, 'n', 'u', 'l', 'l')
+		w.endValue()
+	}
+}
+
+// Quoted writes value written by write as fields with string tag option are:
+// numbers and bools are quoted, strings are written as is
+// and empty string is written if write writes nothing
+func (w *Writer) Quoted(write func(q *Writer)) {
+	if !w.beginValue() {
+		return
+	}
+	start := len(w.buf)
+	q := Writer{buf: w.buf, flags: w.flags}
+	write(&q)
+	w.buf = q.buf
+	switch {
+	case q.err != nil:
+		w.err = q.err
+		return
+	case q.depth != 0 || q.afterKey:
+		w.err = errWriterUnclosed
+		return
+	case !q.comma:
+		w.buf = append(w.buf, '"', '"')
+	case w.buf[start] == '{' || w.buf[start] == '[':
+		w.err = errWriterQuoted
+		return
+	case w.buf[start] != '"':
+		w.buf = append(w.buf, '"')
+		copy(w.buf[start+1:], w.buf[start:])
+		w.buf[start] = '"'
+		w.buf = append(w.buf, '"')
+	}
+	w.endValue()
+}
+
+// Raw writes already encoded JSON value as is
+func (w *Writer) Raw(data []byte) {
+	if !w.beginValue() {
+		return
+	}
+	if len(data) == 0 {
+		w.err = errWriterEmptyRaw
+		return
+	}
+	w.buf = append(w.buf, data...)
+	w.endValue()
+}
+
+// Value writes value by cached type encoder with writer flags,
+// null is written if the encoder writes nothing
+func (w *Writer) Value(value any) {
+	if !w.beginValue() {
+		return
+	}
+	eface := zgo.UnpackEface(value)
+	if eface.Type != nil {
+		n := len(w.buf)
+		w.buf, w.err = getTypeEncoder(eface.Type, w.flags)(w.buf, eface.Data)
+		runtime.KeepAlive(value)
+		if w.err != nil || len(w.buf) != n {
+			w.endValue()
+			return
+		}
+	}
+	w.buf = append(w.buf, 'n', 'u', 'l', 'l')
+	w.endValue()
+}
+
+// Field writes key and value pointed by ptr as struct field with json tag options,
+// e.g. "omitempty", by cached type encoder with writer flags. The key is not written
+// if the encoder writes nothing, as omitted fields of structs are
+func (w *Writer) Field(key string, ptr any, options string) {
+	if w.err != nil {
+		return
+	}
+	eface := zgo.UnpackEface(ptr)
+	if eface.Type == nil || eface.Type.Kind() != reflect.Pointer || eface.Data == nil {
+		w.err = errWriterFieldPtr
+		return
+	}
+
+	n, comma := len(w.buf), w.comma
+	w.KeyBytes(zgo.S2B(key))
+	if w.err != nil {
+		return
+	}
+	valIndex := len(w.buf)
+	w.buf, w.err = encodeAt(w.buf, eface.Type.Native().Elem(), parseFieldOptions(w.flags, options), eface.Data)
+	runtime.KeepAlive(ptr)
+	if w.err != nil {
+		return
+	}
+	if len(w.buf) == valIndex {
+		w.buf = w.buf[:n]
+		w.comma = comma
+		w.afterKey = false
+		return
+	}
+	w.endValue()
+}
@@ -0,0 +1,148 @@
+package jessy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/avpetkun/jessy-go/require"
+)
+
+type writerPoint struct {
+	X, Y int64
+	Tags []string
+}
+
+func (p writerPoint) AppendJSON(dst []byte) ([]byte, error) {
+	w := NewWriter(dst)
+	w.ObjectStart()
+	w.Key("xy")
+	w.ArrayStart()
+	w.Int64(p.X)
+	w.Int64(p.Y)
+	w.ArrayEnd()
+	w.Key("tags")
+	w.ArrayStart()
+	for _, tag := range p.Tags {
+		w.String(tag)
+	}
+	w.ArrayEnd()
+	w.ObjectEnd()
+	return w.Result()
+}
+
+func TestWriter(t *testing.T) {
+	data, err := Marshal(map[string]writerPoint{"p": {1, -2, []string{"a<b", "c"}}})
+	require.NoError(t, err)
+	require.Equal(t, `{"p":{"xy":[1,-2],"tags":["a\u003cb","c"]}}`, string(data))
+
+	w := NewWriter(nil)
+	w.ArrayStart()
+	w.Null()
+	w.Bool(true)
+	w.Bool(false)
+	w.Int(-1)
+	w.Uint(2)
+	w.Uint64(math.MaxUint64)
+	w.Float32(0.1)
+	w.Float64(1e-7)
+	w.Bytes([]byte{0xfb, 0xff})
+	w.StringBytes([]byte("s"))
+	w.Raw([]byte(`{"raw":1}`))
+	w.Value(map[string]int{"b": 2, "a": 1})
+	w.Value(nil)
+	w.ObjectStart()
+	w.ObjectEnd()
+	w.ArrayEnd()
+	data, err = w.Result()
+	require.NoError(t, err)
+	require.Equal(t, `[null,true,false,-1,2,18446744073709551615,0.1,1e-7,"+/8=","s",{"raw":1},{"a":1,"b":2},null,{}]`, string(data))
+
+	w = NewWriterFlags(nil, EncodeStandard|BytesHex|EscapeUnicode|FloatPrecision(2))
+	w.ObjectStart()
+	w.Key("é")
+	w.Bytes([]byte{0xab})
+	w.Key("f")
+	w.Float64(1.005)
+	w.ObjectEnd()
+	data, err = w.Result()
+	require.NoError(t, err)
+	require.Equal(t, `{"\u00e9":"ab","f":1.00}`, string(data))
+
+	fields := struct {
+		Zero  int
+		Price float64
+		Ptr   *int
+		Data  []byte
+	}{Price: 2.5}
+	w = NewWriter(nil)
+	w.ObjectStart()
+	w.Field("zero", &fields.Zero, "omitempty")
+	w.Field("price", &fields.Price, "string,precision=2")
+	w.Field("ptr", &fields.Ptr, "omitempty")
+	w.Field("data", &fields.Data, "omitempty,bytes=hex")
+	w.Field("ptr", &fields.Ptr, "")
+	w.ObjectEnd()
+	data, err = w.Result()
+	require.NoError(t, err)
+	require.Equal(t, `{"price":"2.50","ptr":null}`, string(data))
+
+	w = NewWriter(nil)
+	w.ArrayStart()
+	w.Quoted(func(q *Writer) { q.Int64(-1) })
+	w.Quoted(func(q *Writer) { q.Bool(true) })
+	w.Quoted(func(q *Writer) { q.Float32(0.5) })
+	w.Quoted(func(q *Writer) { q.String("a<b") })
+	w.Quoted(func(q *Writer) {})
+	w.ArrayEnd()
+	data, err = w.Result()
+	require.NoError(t, err)
+	require.Equal(t, `["-1","true","0.5","a\u003cb",""]`, string(data))
+
+	misuse := []struct {
+		write func(w *Writer)
+		err   error
+	}{
+		{func(w *Writer) {}, errWriterEmpty},
+		{func(w *Writer) { w.Int(1); w.Int(2) }, errWriterTopValue},
+		{func(w *Writer) { w.ArrayStart() }, errWriterUnclosed},
+		{func(w *Writer) { w.ArrayStart(); w.ObjectEnd() }, errWriterNotObject},
+		{func(w *Writer) { w.ObjectStart(); w.ArrayEnd() }, errWriterNotArray},
+		{func(w *Writer) { w.ObjectStart(); w.Int(1) }, errWriterNoKey},
+		{func(w *Writer) { w.ObjectStart(); w.Key("a"); w.Key("b") }, errWriterNoValue},
+		{func(w *Writer) { w.ObjectStart(); w.Key("a"); w.ObjectEnd() }, errWriterNoValue},
+		{func(w *Writer) { w.ArrayStart(); w.Key("a") }, errWriterNotObject},
+		{func(w *Writer) { w.Key("a") }, errWriterNotObject},
+		{func(w *Writer) { w.Raw(nil) }, errWriterEmptyRaw},
+		{func(w *Writer) { w.Float64(math.NaN()) }, errFloatNum},
+		{func(w *Writer) { w.ObjectStart(); w.Field("a", 1, "") }, errWriterFieldPtr},
+		{func(w *Writer) { w.Field("a", new(int), "") }, errWriterNotObject},
+		{func(w *Writer) { w.Quoted(func(q *Writer) { q.ArrayStart(); q.ArrayEnd() }) }, errWriterQuoted},
+		{func(w *Writer) { w.Quoted(func(q *Writer) { q.ObjectStart() }) }, errWriterUnclosed},
+		{func(w *Writer) { w.Quoted(func(q *Writer) { q.Int(1); q.Int(2) }) }, errWriterTopValue},
+		{func(w *Writer) {
+			for range writerMaxDeep + 1 {
+				w.ArrayStart()
+			}
+		}, errWriterTooDeep},
+	}
+	for _, m := range misuse {
+		w.Reset(nil)
+		m.write(&w)
+		_, err = w.Result()
+		require.Equal(t, m.err, err)
+	}
+
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		w := NewWriter(buf)
+		w.ObjectStart()
+		w.Key("a")
+		w.String("b")
+		w.Key("c")
+		w.Float64(1.5)
+		w.ObjectEnd()
+		buf, _ = w.Result()
+		buf = buf[:0]
+	})
+	require.Equal(t, 0.0, allocs)
+}
@@ -0,0 +1,98 @@
+package zstr
+
+// Palette is a set of ANSI escape sequences to color JSON tokens,
+// empty sequence leaves a token uncolored
+type Palette struct {
+	Key    string
+	String string
+	Number string
+	Bool   string
+	Null   string
+	Punct  string // brackets, commas and colons
+}
+
+// ColorReset is ANSI sequence which resets color after each colored token
+const ColorReset = "\x1b[0m"
+
+// DefaultPalette colors like most of terminal JSON viewers
+var DefaultPalette = Palette{
+	Key:    "\x1b[34;1m",
+	String: "\x1b[32m",
+	Number: "\x1b[36m",
+	Bool:   "\x1b[33m",
+	Null:   "\x1b[90m",
+}
+
+// AppendColorJSON appends src JSON with tokens wrapped into palette colors,
+// spaces and indents of src are kept as is
+func AppendColorJSON(dst, src []byte, p *Palette) []byte {
+	dst = growCap(dst, len(src)*2)
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(src))
+			color := p.String
+			if isKeyEnd(src[end:]) {
+				color = p.Key
+			}
+			dst = appendColored(dst, src[i:end], color)
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(src) && isNumberChar(src[end]) {
+				end++
+			}
+			dst = appendColored(dst, src[i:end], p.Number)
+			i = end
+		case c >= 'a' && c <= 'z':
+			end := i + 1
+			for end < len(src) && src[end] >= 'a' && src[end] <= 'z' {
+				end++
+			}
+			color := p.Bool
+			if c == 'n' {
+				color = p.Null
+			}
+			dst = appendColored(dst, src[i:end], color)
+			i = end
+		case c == '{' || c == '}' || c == '[' || c == ']' || c == ',' || c == ':':
+			dst = appendColored(dst, src[i:i+1], p.Punct)
+			i++
+		default:
+			dst = append(dst, c)
+			i++
+		}
+	}
+	return dst
+}
+
+func appendColored(dst, token []byte, color string) []byte {
+	if color == "" {
+		return append(dst, token...)
+	}
+	dst = append(dst, color...)
+	dst = append(dst, token...)
+	return append(dst, ColorReset...)
+}
+
+// isKeyEnd reports whether string is followed by a colon
+func isKeyEnd(src []byte) bool {
+	for _, c := range src {
+		if !isSpace(c) {
+			return c == ':'
+		}
+	}
+	return false
+}
+
+func isNumberChar(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-'
+}
@@ -0,0 +1,178 @@
+package zstr
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// EscapeFlags extends string escaping of AppendQuotedString
+type EscapeFlags uint8
+
+const (
+	// escape <, > and & as \u003c, \u003e and \u0026
+	EscapeHTML EscapeFlags = 1 << iota
+	// escape every non-ASCII rune as \uXXXX (surrogate pairs for runes above U+FFFF)
+	EscapeUnicode
+	// escape / as \/
+	EscapeSlash
+	// keep invalid UTF-8 bytes as is instead of replacing them with \ufffd
+	KeepInvalidUTF8
+)
+
+// AppendQuotedStringFlags is AppendQuotedString with extended escaping
+func AppendQuotedStringFlags(dst, src []byte, flags EscapeFlags) []byte {
+	dst = growCap(dst, len(src)+2)
+	dst = append(dst, '"')
+	dst = appendEscapedString(dst, src, flags)
+	dst = append(dst, '"')
+	return dst
+}
+
+// appendEscapedString appends string content without quotes
+func appendEscapedString(dst, src []byte, flags EscapeFlags) []byte {
+	escapeHTML := flags&EscapeHTML != 0
+	escapeUnicode := flags&EscapeUnicode != 0
+	escapeSlash := flags&EscapeSlash != 0
+	keepInvalid := flags&KeepInvalidUTF8 != 0
+
+	start := 0
+	srcLen := len(src)
+	for i := 0; i < srcLen; {
+		if b := src[i]; b < utf8.RuneSelf {
+			if (htmlSafeSet[b] || (!escapeHTML && safeSet[b])) && (b != '/' || !escapeSlash) {
+				i++
+				continue
+			}
+			dst = append(dst, src[start:i]...)
+			switch b {
+			case '\\', '"', '/':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', toHex[b>>4], toHex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		n := len(src) - i
+		if n > utf8.UTFMax {
+			n = utf8.UTFMax
+		}
+		c, size := utf8.DecodeRuneInString(string(src[i : i+n]))
+		if c == utf8.RuneError && size == 1 {
+			if keepInvalid {
+				i++
+				continue
+			}
+			dst = append(dst, src[start:i]...)
+			dst = append(dst, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if escapeUnicode || c == '\u2028' || c == '\u2029' {
+			dst = append(dst, src[start:i]...)
+			dst = appendRuneEscape(dst, c)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(dst, src[start:]...)
+}
+
+func appendRuneEscape(dst []byte, c rune) []byte {
+	if c >= 0x10000 {
+		r1, r2 := utf16.EncodeRune(c)
+		dst = appendU16Escape(dst, uint16(r1))
+		return appendU16Escape(dst, uint16(r2))
+	}
+	return appendU16Escape(dst, uint16(c))
+}
+
+func appendU16Escape(dst []byte, c uint16) []byte {
+	return append(dst, '\\', 'u', toHex[c>>12], toHex[c>>8&0xF], toHex[c>>4&0xF], toHex[c&0xF])
+}
+
+// AppendCompactJSONFlags is AppendCompactJSON with extended escaping of strings
+func AppendCompactJSONFlags(dst, src []byte, flags EscapeFlags) []byte {
+	return appendEscapedJSON(dst, src, flags, true)
+}
+
+// AppendEscapeJSONFlags escapes strings of JSON like AppendHTMLEscape
+// but by escape flags, spaces are kept as is
+func AppendEscapeJSONFlags(dst, src []byte, flags EscapeFlags) []byte {
+	return appendEscapedJSON(dst, src, flags, false)
+}
+
+func appendEscapedJSON(dst, src []byte, flags EscapeFlags, compact bool) []byte {
+	// invalid UTF-8 is copied as is unless it must be escaped
+	if flags&EscapeUnicode == 0 {
+		flags |= KeepInvalidUTF8
+	}
+	dst = growCap(dst, len(src))
+	start := 0
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == '"' {
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			dst = append(dst, src[start:i+1]...)
+			if end > len(src) {
+				end = len(src)
+			}
+			dst = appendEscapedStringRaw(dst, src[i+1:end], flags)
+			i = end
+			start = end
+			continue
+		}
+		if compact && isSpace(c) {
+			dst = append(dst, src[start:i]...)
+			start = i + 1
+		}
+	}
+	return append(dst, src[start:]...)
+}
+
+// AppendEscapedJSONString escapes content of JSON string literal by flags like
+// AppendEscapeJSONFlags, existing escape sequences are copied as is
+func AppendEscapedJSONString(dst, src []byte, flags EscapeFlags) []byte {
+	// invalid UTF-8 is copied as is unless it must be escaped
+	if flags&EscapeUnicode == 0 {
+		flags |= KeepInvalidUTF8
+	}
+	return appendEscapedStringRaw(dst, src, flags)
+}
+
+// appendEscapedStringRaw escapes already escaped string content,
+// existing escape sequences are copied as is
+func appendEscapedStringRaw(dst, src []byte, flags EscapeFlags) []byte {
+	start := 0
+	for i := 0; i < len(src); i++ {
+		if src[i] != '\\' {
+			continue
+		}
+		dst = appendEscapedString(dst, src[start:i], flags)
+		end := min(i+2, len(src))
+		dst = append(dst, src[i:end]...)
+		i = end - 1
+		start = end
+	}
+	return appendEscapedString(dst, src[start:], flags)
+}
@@ -0,0 +1,174 @@
+package zstr
+
+import "bytes"
+
+// AppendIndentWidth is AppendIndent which keeps arrays and objects on one line
+// while the line fits into width bytes, e.g. [1, 2, 3] or {"x": 1, "y": 2}
+func AppendIndentWidth(dst, src []byte, prefix, indent string, width int) []byte {
+	dst = append(dst, prefix...)
+	return appendIndentWidth(dst, src, prefix, indent, width)
+}
+
+// AppendIndentInlineWidth is AppendIndentInline with width of AppendIndentWidth
+func AppendIndentInlineWidth(dst, src []byte, prefix, indent string, width int) []byte {
+	return appendIndentWidth(dst, src, prefix, indent, width)
+}
+
+func appendIndentWidth(dst, src []byte, prefix, indent string, width int) []byte {
+	deep := 0
+	lastIndentLen := 0
+	inString := false
+	escaped := false
+
+	// start positions of open containers to collapse them
+	var startsBuf [32]int
+	starts := startsBuf[:0]
+
+	dst = growCap(dst, len(src)*2)
+
+	for _, c := range src {
+		if inString {
+			dst = append(dst, c)
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if isSpace(c) {
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+			dst = append(dst, c)
+		case '{', '[':
+			deep++
+			if width > 0 {
+				starts = append(starts, len(dst))
+			}
+			dst = append(dst, c)
+			dstLen := len(dst)
+			dst = appendNewline(dst, prefix, indent, deep)
+			lastIndentLen = len(dst) - dstLen
+			continue
+		case '}', ']':
+			deep--
+			if lastIndentLen != 0 {
+				dst = dst[:len(dst)-lastIndentLen]
+			} else {
+				dst = appendNewline(dst, prefix, indent, deep)
+			}
+			dst = append(dst, c)
+			if width > 0 && len(starts) != 0 {
+				start := starts[len(starts)-1]
+				starts = starts[:len(starts)-1]
+				inner := len(prefix) + len(indent)*(deep+1)
+				outer := len(prefix) + len(indent)*deep
+				dst = CollapseIndent(dst, start, width, inner, outer)
+			}
+		case ',':
+			dst = append(dst, c)
+			dst = appendNewline(dst, prefix, indent, deep)
+		case ':':
+			dst = append(dst, c, ' ')
+		default:
+			dst = append(dst, c)
+		}
+		lastIndentLen = 0
+	}
+	return dst
+}
+
+// CollapseIndent joins indented array or object which is the tail of dst from start
+// into one line like [1, 2, 3] if the line fits into width bytes.
+// Nested containers must be already collapsed, otherwise dst stays unchanged.
+// The inner and outer are lengths of line indents of items and of the closing bracket
+func CollapseIndent(dst []byte, start, width, inner, outer int) []byte {
+	src := dst[start:]
+	if len(src) < 3 || src[1] != '\n' {
+		// empty container
+		return dst
+	}
+	limit := width - (start - bytes.LastIndexByte(dst[:start], '\n') - 1)
+	closing := len(src) - 1 - outer - 1
+
+	// measure one line size
+	size := 0
+	depth := 0
+	inString := false
+	escaped := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		} else {
+			switch c {
+			case '"':
+				inString = true
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			case '\n':
+				if depth != 1 {
+					return dst
+				}
+				if i == closing {
+					i += outer
+					continue
+				}
+				if src[i-1] == ',' {
+					size++ // space after comma
+				}
+				i += inner
+				continue
+			}
+		}
+		if size++; size > limit {
+			return dst
+		}
+	}
+
+	// rewrite in place, one line is never longer
+	w := start
+	inString = false
+	escaped = false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		} else if c == '"' {
+			inString = true
+		} else if c == '\n' {
+			if i == closing {
+				i += outer
+				continue
+			}
+			if src[i-1] == ',' {
+				dst[w] = ' '
+				w++
+			}
+			i += inner
+			continue
+		}
+		dst[w] = c
+		w++
+	}
+	return dst[:w]
+}
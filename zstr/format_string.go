@@ -6,13 +6,17 @@ import (
 )
 
 func AppendBase64String(dst, data []byte) []byte {
-	size := base64.StdEncoding.EncodedLen(len(data)) + 2
+	return AppendBase64StringEncoding(dst, data, base64.StdEncoding)
+}
+
+func AppendBase64StringEncoding(dst, data []byte, enc *base64.Encoding) []byte {
+	size := enc.EncodedLen(len(data)) + 2
 
 	i := len(dst)
 	dst = growCap(dst, size)[:i+size]
 
 	dst[i] = '"'
-	base64.StdEncoding.Encode(dst[i+1:], data)
+	enc.Encode(dst[i+1:], data)
 	dst[len(dst)-1] = '"'
 
 	return dst
@@ -41,6 +45,26 @@ func AppendHexString(dst, data []byte) []byte {
 	return dst
 }
 
+// AppendQuotedHex appends quoted lowercase hex without 0x prefix
+func AppendQuotedHex(dst, data []byte) []byte {
+	size := len(data)*2 + 2
+
+	i := len(dst)
+	dst = growCap(dst, size)[:i+size]
+
+	dst[i] = '"'
+	i++
+
+	for _, v := range data {
+		dst[i] = toHex[v>>4]
+		dst[i+1] = toHex[v&0x0f]
+		i += 2
+	}
+	dst[i] = '"'
+
+	return dst
+}
+
 func AppendHex(dst, data []byte) []byte {
 	size := len(data)*2 + 2
 
@@ -86,9 +110,10 @@ func AppendQuotedString(dst, src []byte, escapeHtml bool) []byte {
 	dst = append(dst, '"')
 	start := 0
 	srcLen := len(src)
-	for i := 0; i < srcLen; i++ {
+	for i := 0; i < srcLen; {
 		if b := src[i]; b < utf8.RuneSelf {
 			if htmlSafeSet[b] || (!escapeHtml && safeSet[b]) {
+				i++
 				continue
 			}
 			dst = append(dst, src[start:i]...)
@@ -261,6 +286,12 @@ func AppendIndent(dst, src []byte, prefix, indent string) []byte {
 	return dst
 }
 
+// AppendIndentInline is AppendIndent for JSON embedded into already indented output,
+// it doesn't begin with prefix and skips insignificant spaces of src
+func AppendIndentInline(dst, src []byte, prefix, indent string) []byte {
+	return appendIndentWidth(dst, src, prefix, indent, 0)
+}
+
 func appendNewline(dst []byte, prefix, indent string, deep int) []byte {
 	dst = append(dst, '\n')
 	dst = append(dst, prefix...)
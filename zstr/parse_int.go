@@ -8,55 +8,43 @@ import (
 var errEmptyNumber = fmt.Errorf("parsing empty number")
 
 func ParseUint64(s []byte) (v uint64, err error) {
-	if len(s) == 0 {
-		return 0, errEmptyNumber
-	}
-	var c byte
-	var n uint64
-	for _, c = range s {
-		c -= '0'
-		if c > 9 {
-			v = 0
-			err = fmt.Errorf("invalid number symbol '%s' in number %s", string(c), s)
-			return
-		}
-		n = v*10 + uint64(c)
-		if n < v {
-			err = fmt.Errorf("too big uint64 number '%s'", s)
-			return
-		}
-		v = n
-	}
-	return
+	return parseUint64(s, s, math.MaxUint64, "uint64")
 }
 
 func ParseInt64(s []byte) (v int64, err error) {
 	if len(s) == 0 {
 		return 0, errEmptyNumber
 	}
-	var neg bool
+	digits := s
+	limit := uint64(math.MaxInt64)
 	if s[0] == '-' {
-		s = s[1:]
-		neg = true
+		digits = s[1:]
+		limit++
+	}
+	u, err := parseUint64(digits, s, limit, "int64")
+	if err != nil {
+		return 0, err
+	}
+	if len(digits) != len(s) {
+		return int64(-u), nil
+	}
+	return int64(u), nil
+}
+
+// parseUint64 parses digits of number s up to limit
+func parseUint64(digits, s []byte, limit uint64, typ string) (v uint64, err error) {
+	if len(digits) == 0 {
+		return 0, errEmptyNumber
 	}
-	var c byte
-	var n int64
-	for _, c = range s {
+	for _, c := range digits {
 		c -= '0'
 		if c > 9 {
-			v = 0
-			err = fmt.Errorf("invalid number symbol '%s' in number %s", string(c), s)
-			return
+			return 0, fmt.Errorf("invalid number symbol '%s' in number %s", string(c+'0'), s)
 		}
-		n = v*10 + int64(c)
-		if n < v && n != math.MinInt64 {
-			err = fmt.Errorf("too big int64 number '%s'", s)
-			return
+		if v > (limit-uint64(c))/10 {
+			return 0, fmt.Errorf("too big %s number '%s'", typ, s)
 		}
-		v = n
-	}
-	if neg {
-		v = -v
+		v = v*10 + uint64(c)
 	}
 	return
 }
@@ -24,6 +24,11 @@ func TestParseUint64(t *testing.T) {
 			t.Errorf("expected %d actual %d", c.V, v)
 		}
 	}
+	for _, s := range []string{"", "-1", "1e3", "18446744073709551616", "27000000000000000000"} {
+		if v, err := ParseUint64([]byte(s)); err == nil {
+			t.Errorf("expected error of %q, actual %d", s, v)
+		}
+	}
 }
 
 func TestParseInt64(t *testing.T) {
@@ -46,6 +51,11 @@ func TestParseInt64(t *testing.T) {
 			t.Errorf("expected %d actual %d", c.V, v)
 		}
 	}
+	for _, s := range []string{"", "-", "1.5", "9223372036854775808", "-9223372036854775809", "99999999999999999999"} {
+		if v, err := ParseInt64([]byte(s)); err == nil {
+			t.Errorf("expected error of %q, actual %d", s, v)
+		}
+	}
 }
 
 func BenchmarkParseUint64(b *testing.B) {
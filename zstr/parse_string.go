@@ -0,0 +1,78 @@
+package zstr
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// AppendUnescapedString appends JSON string content src (without quotes)
+// with escape sequences decoded, it returns false on invalid escape.
+// Invalid surrogates are decoded as U+FFFD, other bytes are copied as is
+func AppendUnescapedString(dst, src []byte) ([]byte, bool) {
+	dst = growCap(dst, len(src))
+	start := 0
+	for i := 0; i < len(src); i++ {
+		if src[i] != '\\' {
+			continue
+		}
+		dst = append(dst, src[start:i]...)
+		if i++; i == len(src) {
+			return dst, false
+		}
+		switch c := src[i]; c {
+		case '"', '\\', '/':
+			dst = append(dst, c)
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r, ok := parseU16Escape(src[i+1:])
+			if !ok {
+				return dst, false
+			}
+			i += 4
+			if utf16.IsSurrogate(r) {
+				// low surrogate must follow the high one
+				r2, ok := rune(0), false
+				if i+2 < len(src) && src[i+1] == '\\' && src[i+2] == 'u' {
+					r2, ok = parseU16Escape(src[i+3:])
+				}
+				if r = utf16.DecodeRune(r, r2); ok && r != utf8.RuneError {
+					i += 6
+				}
+			}
+			dst = utf8.AppendRune(dst, r)
+		default:
+			return dst, false
+		}
+		start = i + 1
+	}
+	return append(dst, src[start:]...), true
+}
+
+func parseU16Escape(s []byte) (r rune, ok bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	for _, c := range s[:4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c -= 'a' - 10
+		case 'A' <= c && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}